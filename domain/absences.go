@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// Absence is a period when the user must not be assigned to reviews.
+type Absence struct {
+	AbsenceID int64     `json:"absence_id" db:"absence_id"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	From      time.Time `json:"from" db:"starts_at"`
+	To        time.Time `json:"to" db:"ends_at"`
+	Reason    string    `json:"reason" db:"reason"`
+}
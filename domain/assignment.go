@@ -0,0 +1,55 @@
+package domain
+
+// Candidate is a team member considered for a review.
+type Candidate struct {
+	UserID      string `json:"user_id" db:"user_id"`
+	TeamName    string `json:"team_name" db:"team_name"`
+	IsActive    bool   `json:"is_active" db:"is_active"`
+	Absent      bool   `json:"absent" db:"absent"`
+	OpenReviews int    `json:"open_reviews" db:"open_reviews"`
+	// Capacity is the maximum of OPEN reviews, 0 means unlimited.
+	Capacity int `json:"capacity" db:"capacity"`
+}
+
+func (c Candidate) AtCapacity() bool {
+	return c.Capacity > 0 && c.OpenReviews >= c.Capacity
+}
+
+// ValidMaxOpenReviews reports whether the value may be stored as a review limit of
+// a user or a team: nil means no limit, otherwise it must be at least 1.
+func ValidMaxOpenReviews(maxOpenReviews *int) bool {
+	return maxOpenReviews == nil || *maxOpenReviews >= 1
+}
+
+type RejectionReason string
+
+const (
+	REJECTED_AUTHOR           RejectionReason = "AUTHOR"
+	REJECTED_ALREADY_ASSIGNED RejectionReason = "ALREADY_ASSIGNED"
+	REJECTED_INACTIVE         RejectionReason = "INACTIVE"
+	REJECTED_UNAVAILABLE      RejectionReason = "UNAVAILABLE"
+	REJECTED_AT_CAPACITY      RejectionReason = "AT_CAPACITY"
+	REJECTED_NOT_SELECTED     RejectionReason = "NOT_SELECTED"
+)
+
+type RejectedCandidate struct {
+	UserID      string          `json:"user_id"`
+	TeamName    string          `json:"team_name"`
+	Reason      RejectionReason `json:"reason"`
+	OpenReviews int             `json:"open_reviews"`
+}
+
+// ReviewerSelection is the outcome of picking reviewers with explanations.
+type ReviewerSelection struct {
+	Reviewers         []string            `json:"reviewers"`
+	FallbackReviewers []string            `json:"fallback_reviewers"`
+	Rejected          []RejectedCandidate `json:"rejected"`
+}
+
+type AssignmentPreview struct {
+	AuthorID          string `json:"author_id"`
+	TeamName          string `json:"team_name"`
+	RequiredReviewers int    `json:"required_reviewers"`
+	NeedMoreReviewers bool   `json:"need_more_reviewers"`
+	ReviewerSelection
+}
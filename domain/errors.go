@@ -12,10 +12,23 @@ const (
 	TEAM_EXISTS  ErrorCode = "Team exists"
 	PR_EXISTS    ErrorCode = "Pull request exists"
 	PR_MERGED    ErrorCode = "Pull request merged"
+	PR_CLOSED    ErrorCode = "Pull request closed"
+	PR_DRAFT     ErrorCode = "Pull request is a draft"
 	NOT_ASSIGNED ErrorCode = "Not assigned"
 	NO_CANDIDATE ErrorCode = "No candidate"
 	NOT_FOUND    ErrorCode = "Not found"
 
+	USER_EXISTS  ErrorCode = "User exists"
+	USER_IS_BUSY ErrorCode = "User has open pull requests"
+
+	INVALID_REVIEWER ErrorCode = "Invalid reviewer"
+	REVIEWERS_LIMIT  ErrorCode = "Reviewers limit reached"
+
+	PRECONDITION_FAILED ErrorCode = "Precondition failed"
+
+	IDEMPOTENCY_MISMATCH    ErrorCode = "Idempotency key mismatch"
+	IDEMPOTENCY_IN_PROGRESS ErrorCode = "Idempotency key in progress"
+
 	INTERNAL     ErrorCode = "Internal server error"
 	BAD_REQUEST  ErrorCode = "Bad request"
 	UNAUTHORIZED ErrorCode = "Unauthorized"
@@ -56,6 +69,12 @@ func WriteError(w http.ResponseWriter, response *ErrorResponse) {
 	}
 }
 
+// HasCode reports whether err is an ErrorResponse with the given code.
+func HasCode(err error, code ErrorCode) bool {
+	var errR *ErrorResponse
+	return errors.As(err, &errR) && errR.Code == code
+}
+
 func ConvertToErrorResponse(err error) *ErrorResponse {
 	var errR *ErrorResponse
 	if errors.As(err, &errR) {
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord is a stored response for an Idempotency-Key.
+type IdempotencyRecord struct {
+	Key         string `db:"idempotency_key"`
+	RequestHash string `db:"request_hash"`
+	// StatusCode is nil while the first request with the key is still running.
+	StatusCode *int `db:"status_code"`
+	// Headers are the response headers encoded as a JSON object of string lists.
+	Headers   []byte    `db:"response_headers"`
+	Response  []byte    `db:"response_body"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
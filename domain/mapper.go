@@ -8,6 +8,10 @@ func statusCodeMapper(err *ErrorResponse) int {
 		return 404
 	case PR_MERGED:
 		return 409
+	case PR_CLOSED:
+		return 409
+	case PR_DRAFT:
+		return 409
 	case NOT_ASSIGNED:
 		return 409
 	case NO_CANDIDATE:
@@ -18,6 +22,20 @@ func statusCodeMapper(err *ErrorResponse) int {
 		return 401
 	case PR_EXISTS:
 		return 409
+	case USER_EXISTS:
+		return 409
+	case USER_IS_BUSY:
+		return 409
+	case INVALID_REVIEWER:
+		return 409
+	case REVIEWERS_LIMIT:
+		return 409
+	case PRECONDITION_FAILED:
+		return 412
+	case IDEMPOTENCY_MISMATCH:
+		return 422
+	case IDEMPOTENCY_IN_PROGRESS:
+		return 409
 	default:
 		return 500
 	}
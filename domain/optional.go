@@ -0,0 +1,25 @@
+package domain
+
+import "encoding/json"
+
+// Optional is a field of a partial update which tells an omitted value (Set is
+// false) from an explicit null (Set is true, Value is nil).
+type Optional[T any] struct {
+	Set   bool
+	Value *T
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Value = nil
+		return nil
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	o.Value = &value
+	return nil
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+type PullRequestSort string
+
+const (
+	SORT_BY_CREATED_AT PullRequestSort = "created_at"
+	SORT_BY_MERGED_AT  PullRequestSort = "merged_at"
+	SORT_BY_ID         PullRequestSort = "pull_request_id"
+)
+
+const (
+	DefaultPullRequestPageSize = 50
+	MaxPullRequestPageSize     = 100
+)
+
+// PullRequestFilter selects pull requests for a list page, zero fields are not applied.
+type PullRequestFilter struct {
+	Status            Status
+	AuthorID          string
+	ReviewerID        string
+	TeamName          string
+	CreatedFrom       *time.Time
+	CreatedTo         *time.Time
+	MergedFrom        *time.Time
+	MergedTo          *time.Time
+	NeedMoreReviewers *bool
+
+	SortBy PullRequestSort
+	Desc   bool
+	Limit  int
+	After  *PullRequestCursor
+}
+
+// PullRequestCursor points at the last pull request of a page: its value of the
+// sort field and its id as a tie-breaker. List pages also record the sort field
+// and order, as the cursor means nothing under another one.
+type PullRequestCursor struct {
+	Value  string          `json:"v"`
+	ID     string          `json:"id"`
+	SortBy PullRequestSort `json:"s,omitempty"`
+	Desc   bool            `json:"d,omitempty"`
+}
+
+func (c PullRequestCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodePullRequestCursor(cursor string) (PullRequestCursor, error) {
+	var result PullRequestCursor
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return result, err
+	}
+
+	err = json.Unmarshal(raw, &result)
+	return result, err
+}
+
+type PullRequestPage struct {
+	PullRequests []PullRequest `json:"pull_requests"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
+}
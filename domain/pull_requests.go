@@ -7,8 +7,10 @@ import (
 type Status string
 
 const (
+	DRAFT  Status = "DRAFT"
 	OPEN   Status = "OPEN"
 	MERGED Status = "MERGED"
+	CLOSED Status = "CLOSED"
 )
 
 type PullRequest struct {
@@ -17,9 +19,33 @@ type PullRequest struct {
 	AuthorID          string    `json:"author_id" db:"author_id"`
 	Status            Status    `json:"status" db:"status"`
 	AssignedReviewers []string  `json:"assigned_reviewers"`
+	FallbackReviewers []string  `json:"fallback_reviewers,omitempty"`
 	NeedMoreReviewers bool      `json:"need_more_reviewers" db:"need_more_reviewers"`
 	CreatedAt         time.Time `json:"created_at" db:"created_at"`
 	MergedAt          time.Time `json:"merged_at" db:"merged_at"`
+	ClosedAt          time.Time `json:"closed_at" db:"closed_at"`
+	Version           int64     `json:"version" db:"version"`
+	PullRequestMetadata
+}
+
+// PullRequestMetadata holds optional descriptive fields of a pull request.
+type PullRequestMetadata struct {
+	URL          string `json:"url,omitempty" db:"url"`
+	Repository   string `json:"repository,omitempty" db:"repository"`
+	SourceBranch string `json:"source_branch,omitempty" db:"source_branch"`
+	TargetBranch string `json:"target_branch,omitempty" db:"target_branch"`
+	Description  string `json:"description,omitempty" db:"description"`
+}
+
+// PullRequestUpdate lists the fields to change, nil fields are left as is.
+type PullRequestUpdate struct {
+	PullRequestName *string
+	AuthorID        *string
+	URL             *string
+	Repository      *string
+	SourceBranch    *string
+	TargetBranch    *string
+	Description     *string
 }
 
 type PullRequestShort struct {
@@ -28,3 +54,17 @@ type PullRequestShort struct {
 	AuthorID        string `json:"author_id" db:"author_id"`
 	Status          Status `json:"status" db:"status"`
 }
+
+type BackfillResult struct {
+	PullRequestID     string   `json:"pull_request_id"`
+	AddedReviewers    []string `json:"added_reviewers"`
+	AssignedReviewers []string `json:"assigned_reviewers"`
+	NeedMoreReviewers bool     `json:"need_more_reviewers"`
+}
+
+// ReassignmentResult describes a pull request whose reviewers were taken away
+// and replaced.
+type ReassignmentResult struct {
+	RemovedReviewers []string `json:"removed_reviewers"`
+	BackfillResult
+}
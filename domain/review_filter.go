@@ -0,0 +1,17 @@
+package domain
+
+// ReviewFilter selects pull requests reviewed by a user for a page ordered by
+// created_at (oldest first unless Desc) and pull_request_id.
+type ReviewFilter struct {
+	ReviewerID string
+	Statuses   []Status
+
+	Desc  bool
+	Limit int
+	After *PullRequestCursor
+}
+
+type ReviewPage struct {
+	PullRequests []PullRequest `json:"pull_requests"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
+}
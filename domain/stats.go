@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 type PullRequestStats struct {
 	UserID          string `json:"user_id"`
 	UserName        string `json:"user_name"`
@@ -7,4 +9,57 @@ type PullRequestStats struct {
 	AssignedPRCount int    `json:"assigned_pr_count"`
 	Open            int    `json:"open_pull_requests"`
 	Merged          int    `json:"merged_pull_requests"`
+	Capacity        *int   `json:"capacity"`
+	CurrentLoad     int    `json:"current_load"`
+}
+
+type TeamStats struct {
+	TeamName               string  `json:"team_name" db:"team_name"`
+	AuthoredPRCount        int     `json:"authored_pr_count" db:"authored_pr_count"`
+	ReviewCount            int     `json:"review_count" db:"review_count"`
+	MergedReviewCount      int     `json:"merged_review_count" db:"merged_review_count"`
+	AvgReviewersPerPR      float64 `json:"avg_reviewers_per_pr" db:"avg_reviewers_per_pr"`
+	NeedMoreReviewersShare float64 `json:"need_more_reviewers_share" db:"need_more_reviewers_share"`
+}
+
+// StatsFilter narrows statistics down to pull requests created in [From, To) with
+// the given status and to one team, zero fields are not applied. Time to merge
+// statistics bound merged_at instead of created_at.
+type StatsFilter struct {
+	From     *time.Time
+	To       *time.Time
+	TeamName string
+	Status   Status
+}
+
+type TimeToMergeGroup string
+
+const (
+	GROUP_BY_TEAM     TimeToMergeGroup = "team"
+	GROUP_BY_AUTHOR   TimeToMergeGroup = "author"
+	GROUP_BY_REVIEWER TimeToMergeGroup = "reviewer"
+)
+
+// TimeToMerge holds percentiles of the time from the moment a pull request became
+// OPEN to its merge, in seconds, over MergedCount pull requests. Per reviewer it is
+// the time in review: from the reviewer's assignment to the merge, over MergedCount
+// reviews.
+type TimeToMerge struct {
+	MergedCount int     `json:"merged_count" db:"merged_count"`
+	P50         float64 `json:"p50_seconds" db:"p50"`
+	P90         float64 `json:"p90_seconds" db:"p90"`
+	P99         float64 `json:"p99_seconds" db:"p99"`
+}
+
+// TimeToMergeStats is the time to merge of one team or author, or the time in review
+// of one reviewer.
+type TimeToMergeStats struct {
+	Key string `json:"key" db:"group_key"`
+	TimeToMerge
+}
+
+// TimeToMergeDay is the time to merge of pull requests merged on Day (UTC, YYYY-MM-DD).
+type TimeToMergeDay struct {
+	Day string `json:"day" db:"day"`
+	TimeToMerge
 }
@@ -1,12 +1,84 @@
 package domain
 
+import "time"
+
 type Team struct {
-	TeamName string       `json:"team_name" db:"team_name"`
-	Members  []TeamMember `json:"members"`
+	TeamName          string       `json:"team_name" db:"team_name"`
+	RequiredReviewers int          `json:"required_reviewers" db:"required_reviewers"`
+	MaxOpenReviews    *int         `json:"max_open_reviews" db:"max_open_reviews"`
+	FallbackTeams     []string     `json:"fallback_teams"`
+	Members           []TeamMember `json:"members"`
+	Version           int64        `json:"version" db:"version"`
 }
 
 type TeamMember struct {
+	UserID   string    `json:"user_id"`
+	UserName string    `json:"username"`
+	IsActive bool      `json:"is_active"`
+	Absences []Absence `json:"absences,omitempty"`
+}
+
+// TeamSettings describes a partial team update, nil fields stay unchanged.
+type TeamSettings struct {
+	RequiredReviewers *int
+	MaxOpenReviews    Optional[int]
+	FallbackTeams     *[]string
+}
+
+type TeamDeactivation struct {
+	TeamName         string               `json:"team_name"`
+	DeactivatedUsers []string             `json:"deactivated_users"`
+	Reassignments    []ReassignmentResult `json:"reassignments"`
+}
+
+type TeamSummary struct {
+	TeamName          string `json:"team_name" db:"team_name"`
+	RequiredReviewers int    `json:"required_reviewers" db:"required_reviewers"`
+	MaxOpenReviews    *int   `json:"max_open_reviews" db:"max_open_reviews"`
+	MemberCount       int    `json:"member_count" db:"member_count"`
+	ActiveMemberCount int    `json:"active_member_count" db:"active_member_count"`
+	Version           int64  `json:"version" db:"version"`
+}
+
+// TeamMembersRemoval reports what happened to members leaving a team. Removed
+// users are deactivated and left without a team, their OPEN and DRAFT pull
+// requests are closed and their OPEN reviews are reassigned. Moved users keep
+// their reviews and pull requests.
+type TeamMembersRemoval struct {
+	TeamName           string               `json:"team_name"`
+	RemovedUsers       []string             `json:"removed_users"`
+	MovedUsers         []string             `json:"moved_users,omitempty"`
+	MovedTo            string               `json:"moved_to,omitempty"`
+	ClosedPullRequests []string             `json:"closed_pull_requests"`
+	Reassignments      []ReassignmentResult `json:"reassignments"`
+}
+
+type TeamMemberMove struct {
 	UserID   string `json:"user_id"`
-	UserName string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	FromTeam string `json:"from_team"`
+}
+
+// TeamSync is the difference between the stored and the desired team roster.
+// Pull requests and reviews of removed and deactivated users are handled as in
+// TeamMembersRemoval and are only reported when the sync is applied.
+type TeamSync struct {
+	TeamName           string               `json:"team_name"`
+	DryRun             bool                 `json:"dry_run"`
+	Created            bool                 `json:"created"`
+	Added              []string             `json:"added"`
+	Removed            []string             `json:"removed"`
+	Moved              []TeamMemberMove     `json:"moved"`
+	Activated          []string             `json:"activated"`
+	Deactivated        []string             `json:"deactivated"`
+	ClosedPullRequests []string             `json:"closed_pull_requests"`
+	Reassignments      []ReassignmentResult `json:"reassignments"`
+}
+
+// TeamMoveRecord is an entry of the history of users moving between teams.
+type TeamMoveRecord struct {
+	MoveID   int64     `json:"move_id" db:"move_id"`
+	UserID   string    `json:"user_id" db:"user_id"`
+	FromTeam string    `json:"from_team" db:"from_team"`
+	ToTeam   string    `json:"to_team" db:"to_team"`
+	MovedAt  time.Time `json:"moved_at" db:"moved_at"`
 }
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+const (
+	DefaultUserPageSize = 50
+	MaxUserPageSize     = 100
+)
+
+// UserFilter selects users for a directory page ordered by user id, zero fields
+// are not applied. NamePrefix matches the username case-insensitively.
+type UserFilter struct {
+	TeamName   string
+	IsActive   *bool
+	NamePrefix string
+
+	Limit int
+	After *UserCursor
+}
+
+// UserCursor points at the last user of a page.
+type UserCursor struct {
+	ID string `json:"id"`
+}
+
+func (c UserCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeUserCursor(cursor string) (UserCursor, error) {
+	var result UserCursor
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return result, err
+	}
+
+	err = json.Unmarshal(raw, &result)
+	return result, err
+}
+
+type UserPage struct {
+	Users      []User `json:"users"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// UserDeletion describes what happened to the work of a deleted user: reassigned
+// OPEN reviews and closed OPEN and DRAFT pull requests they authored. Merged pull
+// requests and reviews are kept.
+type UserDeletion struct {
+	UserID             string               `json:"user_id"`
+	Reassignments      []ReassignmentResult `json:"reassignments"`
+	ClosedPullRequests []string             `json:"closed_pull_requests"`
+}
@@ -1,8 +1,9 @@
 package domain
 
 type User struct {
-	UserID   string `json:"user_id" db:"user_id"`
-	Username string `json:"username" db:"username"`
-	TeamName string `json:"team_name" db:"team_name"`
-	IsActive bool   `json:"is_active" db:"is_active"`
+	UserID         string `json:"user_id" db:"user_id"`
+	Username       string `json:"username" db:"username"`
+	TeamName       string `json:"team_name" db:"team_name"`
+	IsActive       bool   `json:"is_active" db:"is_active"`
+	MaxOpenReviews *int   `json:"max_open_reviews" db:"max_open_reviews"`
 }
@@ -0,0 +1,7 @@
+package domain
+
+// VersionMatches reports whether an entity at version actual satisfies the
+// version expected by the client, nil means any version.
+func VersionMatches(expected *int64, actual int64) bool {
+	return expected == nil || *expected == actual
+}
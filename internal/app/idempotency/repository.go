@@ -0,0 +1,18 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+)
+
+type Repository interface {
+	// Reserve stores the key for a new request. If the key is already taken, the
+	// existing record is returned and reserved is false. Reservations without a
+	// response made before staleBefore are dropped first, as their request will
+	// never complete.
+	Reserve(ctx context.Context, key, requestHash string, expiresAt, staleBefore time.Time) (record domain.IdempotencyRecord, reserved bool, err error)
+	SaveResponse(ctx context.Context, key string, statusCode int, headers []byte, response []byte) error
+	Release(ctx context.Context, key string) error
+}
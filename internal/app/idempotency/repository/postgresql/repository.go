@@ -0,0 +1,169 @@
+package postgresql
+
+import (
+	"context"
+	"log"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/transaction"
+)
+
+const tableName = "idempotency_keys"
+
+type Repository struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencyRepository(db *sqlx.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Reserve(ctx context.Context, key, requestHash string, expiresAt, staleBefore time.Time) (domain.IdempotencyRecord, bool, error) {
+	const op = "idempotency.Repository.Reserve"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.IdempotencyRecord, bool, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.IdempotencyRecord{}, false, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Delete(tableName).
+		Where(sq.Or{
+			sq.Lt{"expires_at": time.Now()},
+			sq.And{sq.Eq{"status_code": nil}, sq.Lt{"created_at": staleBefore}},
+		}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	query, args, err = sq.Insert(tableName).
+		Columns("idempotency_key", "request_hash", "expires_at").
+		Values(key, requestHash, expiresAt).
+		Suffix("ON CONFLICT (idempotency_key) DO NOTHING").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	var record domain.IdempotencyRecord
+	if inserted == 0 {
+		query, args, err = sq.Select("idempotency_key", "request_hash", "status_code", "response_headers", "response_body", "expires_at").
+			From(tableName).
+			Where(sq.Eq{"idempotency_key": key}).
+			PlaceholderFormat(sq.Dollar).
+			ToSql()
+		if err != nil {
+			return fail(domain.INTERNAL, "internal server error", err)
+		}
+
+		if err = tx.GetContext(ctx, &record, query, args...); err != nil {
+			return fail(domain.INTERNAL, "internal server error", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return record, inserted > 0, nil
+}
+
+func (r *Repository) SaveResponse(ctx context.Context, key string, statusCode int, headers []byte, response []byte) error {
+	const op = "idempotency.Repository.SaveResponse"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Update(tableName).
+		Set("status_code", statusCode).
+		Set("response_headers", headers).
+		Set("response_body", response).
+		Where(sq.Eq{"idempotency_key": key}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) Release(ctx context.Context, key string) error {
+	const op = "idempotency.Repository.Release"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Delete(tableName).
+		Where(sq.Eq{"idempotency_key": key}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
+}
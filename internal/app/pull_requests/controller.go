@@ -9,8 +9,12 @@ import (
 
 type Controller interface {
 	CreatePullRequest(w http.ResponseWriter, r *http.Request)
+	PreviewAssignment(w http.ResponseWriter, r *http.Request)
 	MergePullRequest(w http.ResponseWriter, r *http.Request)
 	ReassignPullRequest(w http.ResponseWriter, r *http.Request)
+	AddReviewer(w http.ResponseWriter, r *http.Request)
+	RemoveReviewer(w http.ResponseWriter, r *http.Request)
+	BackfillPullRequests(w http.ResponseWriter, r *http.Request)
 
 	SetupRoutes(r chi.Router, cfg *config.Config)
 }
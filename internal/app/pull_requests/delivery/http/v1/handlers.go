@@ -1,9 +1,13 @@
 package v1
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/leoscrowi/pr-assignment-service/domain"
 	"github.com/leoscrowi/pr-assignment-service/internal/app/pull_requests"
@@ -32,9 +36,13 @@ func (c *PullRequestController) CreatePullRequest(w http.ResponseWriter, r *http
 	}
 
 	pullRequest := &domain.PullRequest{
-		PullRequestID:   req.PullRequestID,
-		PullRequestName: req.PullRequestName,
-		AuthorID:        req.AuthorID,
+		PullRequestID:       req.PullRequestID,
+		PullRequestName:     req.PullRequestName,
+		AuthorID:            req.AuthorID,
+		PullRequestMetadata: req.PullRequestMetadata,
+	}
+	if req.Draft {
+		pullRequest.Status = domain.DRAFT
 	}
 
 	pr, err := c.usecase.CreatePullRequest(r.Context(), pullRequest)
@@ -46,9 +54,34 @@ func (c *PullRequestController) CreatePullRequest(w http.ResponseWriter, r *http
 	var resp = dtos.CreatePRResponse{
 		PR: pr,
 	}
+	utils.SetETag(w, pr.Version)
 	utils.WriteHeader(w, http.StatusCreated, &resp)
 }
 
+func (c *PullRequestController) PreviewAssignment(w http.ResponseWriter, r *http.Request) {
+	var req dtos.PreviewAssignmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	if req.AuthorID == "" {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
+		return
+	}
+
+	preview, err := c.usecase.PreviewAssignment(r.Context(), req.AuthorID)
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.PreviewAssignmentResponse{
+		Preview: preview,
+	}
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
 func (c *PullRequestController) ReassignPullRequest(w http.ResponseWriter, r *http.Request) {
 	var req dtos.ReassignPRRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -61,7 +94,7 @@ func (c *PullRequestController) ReassignPullRequest(w http.ResponseWriter, r *ht
 		return
 	}
 
-	pr, replacedBy, err := c.usecase.ReassignPullRequest(r.Context(), req.PullRequestID, req.OldUserID)
+	pr, replacedBy, err := c.usecase.ReassignPullRequest(r.Context(), req.PullRequestID, req.OldUserID, req.NewUserID, utils.IfMatchVersion(r))
 	if err != nil {
 		domain.WriteError(w, domain.ConvertToErrorResponse(err))
 		return
@@ -71,6 +104,7 @@ func (c *PullRequestController) ReassignPullRequest(w http.ResponseWriter, r *ht
 		PR:         pr,
 		ReplacedBy: replacedBy,
 	}
+	utils.SetETag(w, pr.Version)
 	utils.WriteHeader(w, http.StatusOK, &resp)
 }
 
@@ -86,7 +120,7 @@ func (c *PullRequestController) MergePullRequest(w http.ResponseWriter, r *http.
 		return
 	}
 
-	pr, err := c.usecase.MergePullRequest(r.Context(), req.PullRequestID)
+	pr, err := c.usecase.MergePullRequest(r.Context(), req.PullRequestID, utils.IfMatchVersion(r))
 	if err != nil {
 		domain.WriteError(w, domain.ConvertToErrorResponse(err))
 		return
@@ -95,5 +129,277 @@ func (c *PullRequestController) MergePullRequest(w http.ResponseWriter, r *http.
 	var resp = dtos.MergePRResponse{
 		PR: pr,
 	}
+	utils.SetETag(w, pr.Version)
 	utils.WriteHeader(w, http.StatusOK, &resp)
 }
+
+func (c *PullRequestController) UpdatePullRequest(w http.ResponseWriter, r *http.Request) {
+	var req dtos.UpdatePRRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	if req.PullRequestID == "" || (req.PullRequestName != nil && *req.PullRequestName == "") || (req.AuthorID != nil && *req.AuthorID == "") {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
+		return
+	}
+
+	update := domain.PullRequestUpdate{
+		PullRequestName: req.PullRequestName,
+		AuthorID:        req.AuthorID,
+		URL:             req.URL,
+		Repository:      req.Repository,
+		SourceBranch:    req.SourceBranch,
+		TargetBranch:    req.TargetBranch,
+		Description:     req.Description,
+	}
+
+	pr, err := c.usecase.UpdatePullRequest(r.Context(), req.PullRequestID, update, utils.IfMatchVersion(r))
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.UpdatePRResponse{
+		PR: pr,
+	}
+	utils.SetETag(w, pr.Version)
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func (c *PullRequestController) ClosePullRequest(w http.ResponseWriter, r *http.Request) {
+	c.changeStatus(w, r, c.usecase.ClosePullRequest)
+}
+
+func (c *PullRequestController) MarkReady(w http.ResponseWriter, r *http.Request) {
+	c.changeStatus(w, r, c.usecase.MarkReady)
+}
+
+func (c *PullRequestController) ReopenPullRequest(w http.ResponseWriter, r *http.Request) {
+	c.changeStatus(w, r, c.usecase.ReopenPullRequest)
+}
+
+func (c *PullRequestController) changeStatus(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, pullRequestID string, expectedVersion *int64) (domain.PullRequest, error)) {
+	var req dtos.StatusChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	if req.PullRequestID == "" {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "pull request ID is required", nil))
+		return
+	}
+
+	pr, err := change(r.Context(), req.PullRequestID, utils.IfMatchVersion(r))
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.StatusChangeResponse{
+		PR: pr,
+	}
+	utils.SetETag(w, pr.Version)
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func (c *PullRequestController) AddReviewer(w http.ResponseWriter, r *http.Request) {
+	var req dtos.ReviewerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	if req.PullRequestID == "" || req.UserID == "" {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
+		return
+	}
+
+	pr, err := c.usecase.AddReviewer(r.Context(), req.PullRequestID, req.UserID, utils.IfMatchVersion(r))
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.ReviewerResponse{
+		PR: pr,
+	}
+	utils.SetETag(w, pr.Version)
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func (c *PullRequestController) RemoveReviewer(w http.ResponseWriter, r *http.Request) {
+	var req dtos.ReviewerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	if req.PullRequestID == "" || req.UserID == "" {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
+		return
+	}
+
+	pr, err := c.usecase.RemoveReviewer(r.Context(), req.PullRequestID, req.UserID, utils.IfMatchVersion(r))
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.ReviewerResponse{
+		PR: pr,
+	}
+	utils.SetETag(w, pr.Version)
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func (c *PullRequestController) BackfillPullRequests(w http.ResponseWriter, r *http.Request) {
+	var req dtos.BackfillRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+			return
+		}
+	}
+
+	filled, err := c.usecase.BackfillPullRequests(r.Context(), req.TeamName)
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.BackfillResponse{
+		Filled: filled,
+	}
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func (c *PullRequestController) GetPullRequest(w http.ResponseWriter, r *http.Request) {
+	pullRequestID := r.URL.Query().Get("pull_request_id")
+	if pullRequestID == "" {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("pull_request_id is required")))
+		return
+	}
+
+	pr, err := c.usecase.GetPullRequest(r.Context(), pullRequestID)
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.GetPRResponse{
+		PR: pr,
+	}
+	utils.SetETag(w, pr.Version)
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func (c *PullRequestController) ListPullRequests(w http.ResponseWriter, r *http.Request) {
+	filter, err := parsePullRequestFilter(r.URL.Query())
+	if err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	page, err := c.usecase.ListPullRequests(r.Context(), filter)
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.ListPRResponse{
+		PullRequests: page.PullRequests,
+		NextCursor:   page.NextCursor,
+	}
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func parsePullRequestFilter(query url.Values) (domain.PullRequestFilter, error) {
+	filter := domain.PullRequestFilter{
+		AuthorID:   query.Get("author_id"),
+		ReviewerID: query.Get("reviewer_id"),
+		TeamName:   query.Get("team_name"),
+		SortBy:     domain.SORT_BY_CREATED_AT,
+		Limit:      domain.DefaultPullRequestPageSize,
+	}
+
+	if raw := query.Get("status"); raw != "" {
+		switch status := domain.Status(raw); status {
+		case domain.DRAFT, domain.OPEN, domain.MERGED, domain.CLOSED:
+			filter.Status = status
+		default:
+			return filter, fmt.Errorf("unknown status %q", raw)
+		}
+	}
+
+	timeParams := map[string]**time.Time{
+		"created_from": &filter.CreatedFrom,
+		"created_to":   &filter.CreatedTo,
+		"merged_from":  &filter.MergedFrom,
+		"merged_to":    &filter.MergedTo,
+	}
+	for name, target := range timeParams {
+		raw := query.Get(name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, fmt.Errorf("%s: %w", name, err)
+		}
+		*target = &parsed
+	}
+
+	if raw := query.Get("need_more_reviewers"); raw != "" {
+		needMoreReviewers, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, fmt.Errorf("need_more_reviewers: %w", err)
+		}
+		filter.NeedMoreReviewers = &needMoreReviewers
+	}
+
+	if raw := query.Get("sort"); raw != "" {
+		switch sortBy := domain.PullRequestSort(raw); sortBy {
+		case domain.SORT_BY_CREATED_AT, domain.SORT_BY_MERGED_AT, domain.SORT_BY_ID:
+			filter.SortBy = sortBy
+		default:
+			return filter, fmt.Errorf("unknown sort field %q", raw)
+		}
+	}
+
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		filter.Desc = true
+	default:
+		return filter, fmt.Errorf("order must be asc or desc")
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > domain.MaxPullRequestPageSize {
+			return filter, fmt.Errorf("limit must be between 1 and %d", domain.MaxPullRequestPageSize)
+		}
+		filter.Limit = limit
+	}
+
+	if raw := query.Get("cursor"); raw != "" {
+		cursor, err := domain.DecodePullRequestCursor(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid cursor: %w", err)
+		}
+		if cursor.SortBy != filter.SortBy || cursor.Desc != filter.Desc {
+			return filter, fmt.Errorf("cursor was issued for another sort or order")
+		}
+		if filter.SortBy != domain.SORT_BY_ID {
+			if _, err = time.Parse(time.RFC3339Nano, cursor.Value); err != nil {
+				return filter, fmt.Errorf("invalid cursor: %w", err)
+			}
+		}
+		filter.After = &cursor
+	}
+
+	return filter, nil
+}
@@ -8,9 +8,19 @@ import (
 
 func (c *PullRequestController) SetupRoutes(r chi.Router, cfg *config.Config) {
 	r.Route("/pullRequest", func(r chi.Router) {
-		r.Use(middleware.AdminMiddleware(cfg))
-		r.Post("/create", c.CreatePullRequest)
-		r.Patch("/reassign", c.ReassignPullRequest)
-		r.Patch("/merge", c.MergePullRequest)
+		r.With(middleware.AuthMiddleware(cfg)).Get("/get", c.GetPullRequest)
+		r.With(middleware.AuthMiddleware(cfg)).Get("/list", c.ListPullRequests)
+
+		r.With(middleware.AdminMiddleware(cfg)).Post("/create", c.CreatePullRequest)
+		r.With(middleware.AdminMiddleware(cfg)).Post("/preview", c.PreviewAssignment)
+		r.With(middleware.AdminMiddleware(cfg)).Patch("/update", c.UpdatePullRequest)
+		r.With(middleware.AdminMiddleware(cfg)).Patch("/reassign", c.ReassignPullRequest)
+		r.With(middleware.AdminMiddleware(cfg)).Post("/addReviewer", c.AddReviewer)
+		r.With(middleware.AdminMiddleware(cfg)).Post("/removeReviewer", c.RemoveReviewer)
+		r.With(middleware.AdminMiddleware(cfg)).Patch("/merge", c.MergePullRequest)
+		r.With(middleware.AdminMiddleware(cfg)).Patch("/close", c.ClosePullRequest)
+		r.With(middleware.AdminMiddleware(cfg)).Patch("/markReady", c.MarkReady)
+		r.With(middleware.AdminMiddleware(cfg)).Patch("/reopen", c.ReopenPullRequest)
+		r.With(middleware.AdminMiddleware(cfg)).Post("/backfill", c.BackfillPullRequests)
 	})
 }
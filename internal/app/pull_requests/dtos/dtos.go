@@ -6,12 +6,22 @@ type CreatePRRequest struct {
 	PullRequestID   string `json:"pull_request_id"`
 	PullRequestName string `json:"pull_request_name"`
 	AuthorID        string `json:"author_id"`
+	Draft           bool   `json:"draft,omitempty"`
+	domain.PullRequestMetadata
 }
 
 type CreatePRResponse struct {
 	PR domain.PullRequest `json:"pr"`
 }
 
+type PreviewAssignmentRequest struct {
+	AuthorID string `json:"author_id"`
+}
+
+type PreviewAssignmentResponse struct {
+	Preview domain.AssignmentPreview `json:"preview"`
+}
+
 type MergePRRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 }
@@ -20,12 +30,62 @@ type MergePRResponse struct {
 	PR domain.PullRequest `json:"pr"`
 }
 
+type UpdatePRRequest struct {
+	PullRequestID   string  `json:"pull_request_id"`
+	PullRequestName *string `json:"pull_request_name,omitempty"`
+	AuthorID        *string `json:"author_id,omitempty"`
+	URL             *string `json:"url,omitempty"`
+	Repository      *string `json:"repository,omitempty"`
+	SourceBranch    *string `json:"source_branch,omitempty"`
+	TargetBranch    *string `json:"target_branch,omitempty"`
+	Description     *string `json:"description,omitempty"`
+}
+
+type UpdatePRResponse struct {
+	PR domain.PullRequest `json:"pr"`
+}
+
+type StatusChangeRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+}
+
+type StatusChangeResponse struct {
+	PR domain.PullRequest `json:"pr"`
+}
+
 type ReassignPRRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 	OldUserID     string `json:"old_user_id"`
+	NewUserID     string `json:"new_user_id,omitempty"`
 }
 
 type ReassignPRResponse struct {
 	PR         domain.PullRequest `json:"pr"`
 	ReplacedBy string             `json:"replaced_by"`
 }
+
+type ReviewerRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+}
+
+type ReviewerResponse struct {
+	PR domain.PullRequest `json:"pr"`
+}
+
+type BackfillRequest struct {
+	TeamName string `json:"team_name"`
+}
+
+type BackfillResponse struct {
+	Filled []domain.BackfillResult `json:"filled"`
+}
+
+type GetPRResponse struct {
+	PR domain.PullRequest `json:"pr"`
+}
+
+type ListPRResponse struct {
+	PullRequests []domain.PullRequest `json:"pull_requests"`
+	NextCursor   string               `json:"next_cursor,omitempty"`
+}
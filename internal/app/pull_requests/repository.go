@@ -9,14 +9,29 @@ import (
 type Repository interface {
 	CreatePullRequest(ctx context.Context, pr *domain.PullRequest) error
 	MergePullRequest(ctx context.Context, prID string) (domain.PullRequest, error)
+	SetNeedMoreReviewers(ctx context.Context, prID string, needMoreReviewers bool) error
+	SetNeedMoreReviewersByIDs(ctx context.Context, needMoreReviewers map[string]bool) error
+	RefreshNeedMoreReviewers(ctx context.Context, teamName string) error
+	UpdatePullRequest(ctx context.Context, pr *domain.PullRequest) error
+	UpdateStatus(ctx context.Context, prID string, status domain.Status) error
+	LockPullRequest(ctx context.Context, prID string) error
+	LockPullRequests(ctx context.Context, prIDs []string) error
 
 	GetReviewersID(ctx context.Context, prID string) ([]string, error)
 	DeleteReviewer(ctx context.Context, prID, reviewerID string) error
 	AddReviewer(ctx context.Context, prID, reviewerID string) error
+	AddReviewers(ctx context.Context, reviewers map[string][]string) error
+	FindForeignReviewIDs(ctx context.Context, reviewerID string) ([]string, error)
+	FindOpenReviewIDs(ctx context.Context, reviewerIDs []string) ([]string, error)
+	ReleaseOpenReviews(ctx context.Context, reviewerIDs []string) (map[string][]string, error)
 
 	FetchByID(ctx context.Context, prID string) (domain.PullRequest, error)
+	FetchByIDs(ctx context.Context, prIDs []string) ([]domain.PullRequest, error)
 	FetchByIDWithMergeAt(ctx context.Context, prID string) (domain.PullRequest, error)
-	FetchShortByID(ctx context.Context, prID string) (domain.PullRequestShort, error)
+	ListPullRequests(ctx context.Context, filter domain.PullRequestFilter) ([]domain.PullRequest, error)
+	ListReviews(ctx context.Context, filter domain.ReviewFilter) ([]domain.PullRequest, error)
 
-	FindPullRequestsIDByUserID(ctx context.Context, userID string) ([]string, error)
+	FindActiveIDsByAuthors(ctx context.Context, authorIDs []string) ([]string, error)
+	FindIDsByAuthorTeams(ctx context.Context, teamNames []string) ([]string, error)
+	FindUnderstaffedIDs(ctx context.Context, teamName string) ([]string, error)
 }
@@ -0,0 +1,209 @@
+package postgresql
+
+import (
+	"context"
+	"log"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/transaction"
+	"github.com/lib/pq"
+)
+
+const (
+	createdAtExpr = "COALESCE(pr.created_at, '0001-01-01 00:00:00+00'::timestamptz)"
+	mergedAtExpr  = "COALESCE(pr.merged_at, '0001-01-01 00:00:00+00'::timestamptz)"
+	closedAtExpr  = "COALESCE(pr.closed_at, '0001-01-01 00:00:00+00'::timestamptz)"
+)
+
+// ListPullRequests returns up to filter.Limit pull requests matching the filter,
+// ordered by the sort field and pull_request_id. Pages are read with keyset
+// pagination starting right after filter.After.
+func (r *Repository) ListPullRequests(ctx context.Context, filter domain.PullRequestFilter) ([]domain.PullRequest, error) {
+	const op = "pull_requests.Repository.ListPullRequests"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]domain.PullRequest, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	direction := "ASC"
+	compare := ">"
+	if filter.Desc {
+		direction = "DESC"
+		compare = "<"
+	}
+
+	builder := sq.Select(
+		"pr.pull_request_id", "pr.pull_request_name", "pr.author_id", "pr.status",
+		"pr.need_more_reviewers", "pr.version",
+		createdAtExpr+" AS created_at", mergedAtExpr+" AS merged_at", closedAtExpr+" AS closed_at",
+		"pr.url", "pr.repository", "pr.source_branch", "pr.target_branch", "pr.description",
+	).From(tableName + " pr")
+
+	switch filter.SortBy {
+	case domain.SORT_BY_ID:
+		builder = builder.OrderBy("pr.pull_request_id " + direction)
+		if filter.After != nil {
+			builder = builder.Where("pr.pull_request_id "+compare+" ?", filter.After.ID)
+		}
+	default:
+		sortExpr := createdAtExpr
+		if filter.SortBy == domain.SORT_BY_MERGED_AT {
+			sortExpr = mergedAtExpr
+		}
+		builder = builder.OrderBy(sortExpr+" "+direction, "pr.pull_request_id "+direction)
+		if filter.After != nil {
+			builder = builder.Where("("+sortExpr+", pr.pull_request_id) "+compare+" (?::timestamptz, ?)", filter.After.Value, filter.After.ID)
+		}
+	}
+
+	if filter.Status != "" {
+		builder = builder.Where(sq.Eq{"pr.status": filter.Status})
+	}
+	if filter.AuthorID != "" {
+		builder = builder.Where(sq.Eq{"pr.author_id": filter.AuthorID})
+	}
+	if filter.ReviewerID != "" {
+		builder = builder.Where("EXISTS (SELECT 1 FROM "+reviewersTableName+" prr WHERE prr.pull_request_id = pr.pull_request_id AND prr.reviewer_id = ?)", filter.ReviewerID)
+	}
+	if filter.TeamName != "" {
+		builder = builder.Where(sq.Eq{"pr.author_team_name": filter.TeamName})
+	}
+	if filter.CreatedFrom != nil {
+		builder = builder.Where(sq.GtOrEq{"pr.created_at": *filter.CreatedFrom})
+	}
+	if filter.CreatedTo != nil {
+		builder = builder.Where(sq.Lt{"pr.created_at": *filter.CreatedTo})
+	}
+	if filter.MergedFrom != nil {
+		builder = builder.Where(sq.GtOrEq{"pr.merged_at": *filter.MergedFrom})
+	}
+	if filter.MergedTo != nil {
+		builder = builder.Where(sq.Lt{"pr.merged_at": *filter.MergedTo})
+	}
+	if filter.NeedMoreReviewers != nil {
+		builder = builder.Where(sq.Eq{"pr.need_more_reviewers": *filter.NeedMoreReviewers})
+	}
+
+	query, args, err := builder.Limit(uint64(filter.Limit)).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	result := []domain.PullRequest{}
+	if err = tx.SelectContext(ctx, &result, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	prIDs := make([]string, 0, len(result))
+	for _, pr := range result {
+		prIDs = append(prIDs, pr.PullRequestID)
+	}
+
+	reviewers, fallbackReviewers, err := selectReviewersByPullRequests(ctx, tx, prIDs)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	for i := range result {
+		result[i].AssignedReviewers = reviewers[result[i].PullRequestID]
+		result[i].FallbackReviewers = fallbackReviewers[result[i].PullRequestID]
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return result, nil
+}
+
+// reviewRow is a pull request with its reviewers aggregated by the query.
+type reviewRow struct {
+	domain.PullRequest
+	Reviewers         pq.StringArray `db:"reviewers"`
+	FallbackReviewers pq.StringArray `db:"fallback_reviewers"`
+}
+
+// ListReviews returns up to filter.Limit pull requests reviewed by the user with
+// all their reviewers in a single query, ordered by created_at and pull_request_id
+// and starting right after filter.After.
+func (r *Repository) ListReviews(ctx context.Context, filter domain.ReviewFilter) ([]domain.PullRequest, error) {
+	const op = "pull_requests.Repository.ListReviews"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]domain.PullRequest, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	direction := "ASC"
+	compare := ">"
+	if filter.Desc {
+		direction = "DESC"
+		compare = "<"
+	}
+
+	builder := sq.Select(
+		"pr.pull_request_id", "pr.pull_request_name", "pr.author_id", "pr.status",
+		"pr.need_more_reviewers", "pr.version",
+		createdAtExpr+" AS created_at", mergedAtExpr+" AS merged_at", closedAtExpr+" AS closed_at",
+		"pr.url", "pr.repository", "pr.source_branch", "pr.target_branch", "pr.description",
+		"array_agg(co.reviewer_id ORDER BY co.reviewer_id) AS reviewers",
+		"COALESCE(array_agg(co.reviewer_id ORDER BY co.reviewer_id) FILTER (WHERE co.reviewer_team_name IS DISTINCT FROM au.team_name), '{}') AS fallback_reviewers",
+	).
+		From(reviewersTableName+" me").
+		Join(tableName+" pr ON pr.pull_request_id = me.pull_request_id").
+		Join("users au ON au.user_id = pr.author_id").
+		Join(reviewersTableName+" co ON co.pull_request_id = pr.pull_request_id").
+		Where(sq.Eq{"me.reviewer_id": filter.ReviewerID}).
+		GroupBy("pr.pull_request_id", "au.team_name").
+		OrderBy(createdAtExpr+" "+direction, "pr.pull_request_id "+direction)
+
+	if len(filter.Statuses) > 0 {
+		builder = builder.Where(sq.Eq{"pr.status": filter.Statuses})
+	}
+	if filter.After != nil {
+		builder = builder.Where("("+createdAtExpr+", pr.pull_request_id) "+compare+" (?::timestamptz, ?)", filter.After.Value, filter.After.ID)
+	}
+
+	query, args, err := builder.Limit(uint64(filter.Limit)).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	var rows []reviewRow
+	if err = tx.SelectContext(ctx, &rows, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	result := make([]domain.PullRequest, 0, len(rows))
+	for _, row := range rows {
+		pr := row.PullRequest
+		pr.AssignedReviewers = row.Reviewers
+		if len(row.FallbackReviewers) > 0 {
+			pr.FallbackReviewers = row.FallbackReviewers
+		}
+		result = append(result, pr)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return result, nil
+}
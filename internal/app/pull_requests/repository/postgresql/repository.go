@@ -10,9 +10,14 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
 	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/transaction"
+	"github.com/lib/pq"
 )
 
-const tableName = "pull_requests"
+const (
+	tableName       = "pull_requests"
+	metadataColumns = "url, repository, source_branch, target_branch, description"
+)
 
 type Repository struct {
 	db *sqlx.DB
@@ -30,11 +35,11 @@ func (r *Repository) CreatePullRequest(ctx context.Context, pr *domain.PullReque
 		return domain.NewError(code, message, err)
 	}
 
-	tx, err := r.db.BeginTxx(ctx, nil)
+	tx, err := transaction.Begin(ctx, r.db)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	defer func(tx *sqlx.Tx) {
+	defer func(tx *transaction.Tx) {
 		_ = tx.Rollback()
 	}(tx)
 
@@ -46,14 +51,24 @@ func (r *Repository) CreatePullRequest(ctx context.Context, pr *domain.PullReque
 			"status",
 			"need_more_reviewers",
 			"created_at",
+			"url",
+			"repository",
+			"source_branch",
+			"target_branch",
+			"description",
 		).
 		Values(
 			pr.PullRequestID,
 			pr.PullRequestName,
 			pr.AuthorID,
 			pr.Status,
-			len(pr.AssignedReviewers) < 2,
+			pr.NeedMoreReviewers,
 			time.Now(),
+			pr.URL,
+			pr.Repository,
+			pr.SourceBranch,
+			pr.TargetBranch,
+			pr.Description,
 		).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
@@ -72,49 +87,96 @@ func (r *Repository) CreatePullRequest(ctx context.Context, pr *domain.PullReque
 	return nil
 }
 
-func (r *Repository) FindPullRequestsIDByUserID(ctx context.Context, userID string) ([]string, error) {
-	const op = "pull_requests.Repository.FindPullRequestsIDByUserID"
+func (r *Repository) MergePullRequest(ctx context.Context, prID string) (domain.PullRequest, error) {
+	const op = "pull_requests.Repository.MergePullRequest"
 
-	fail := func(code domain.ErrorCode, message string, err error) ([]string, error) {
+	fail := func(code domain.ErrorCode, message string, err error) (domain.PullRequest, error) {
 		log.Printf("%s: %v\n", op, err)
-		return nil, domain.NewError(code, message, err)
+		return domain.PullRequest{}, domain.NewError(code, message, err)
 	}
 
-	tx, err := r.db.BeginTxx(ctx, nil)
+	tx, err := transaction.Begin(ctx, r.db)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	defer func(tx *sqlx.Tx) {
+	defer func(tx *transaction.Tx) {
 		_ = tx.Rollback()
 	}(tx)
 
-	query, args, err := sq.Select(
-		"pull_request_id",
-	).From(reviewersTableName).Where(sq.Eq{"reviewer_id": userID}).PlaceholderFormat(sq.Dollar).ToSql()
+	query, args, err := sq.Update(tableName).
+		Set("status", "MERGED").
+		Set("merged_at", time.Now()).
+		Where(sq.Eq{"pull_request_id": prID}).
+		Suffix("RETURNING pull_request_id, pull_request_name, author_id, status, need_more_reviewers, merged_at, version, " + metadataColumns).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
-	rows, err := tx.QueryxContext(ctx, query, args...)
+	var pr domain.PullRequest
+	if err = tx.GetContext(ctx, &pr, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	reviewers, fallbackReviewers, err := selectReviewers(ctx, tx, prID)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	defer func(rows *sqlx.Rows) {
-		_ = rows.Close()
-	}(rows)
 
-	var result []string
-	for rows.Next() {
-		var reviewerID string
+	updated := domain.PullRequest{
+		PullRequestID:       pr.PullRequestID,
+		PullRequestName:     pr.PullRequestName,
+		AuthorID:            pr.AuthorID,
+		Status:              pr.Status,
+		AssignedReviewers:   reviewers,
+		FallbackReviewers:   fallbackReviewers,
+		NeedMoreReviewers:   pr.NeedMoreReviewers,
+		MergedAt:            pr.MergedAt,
+		Version:             pr.Version,
+		PullRequestMetadata: pr.PullRequestMetadata,
+	}
 
-		if err = rows.Scan(&reviewerID); err != nil {
-			return fail(domain.INTERNAL, "internal server error", err)
-		}
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return updated, nil
+}
+
+func (r *Repository) FetchByIDWithMergeAt(ctx context.Context, prID string) (domain.PullRequest, error) {
+	const op = "pull_requests.Repository.FetchByIDWithMergedAt"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.PullRequest, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.PullRequest{}, domain.NewError(code, message, err)
+	}
 
-		result = append(result, reviewerID)
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
 	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
 
-	if err = rows.Err(); err != nil {
+	query, args, err := sq.Select("pull_request_id", "pull_request_name", "author_id", "status", "need_more_reviewers", "version", "COALESCE(merged_at, '0001-01-01'::timestamp) as merged_at", metadataColumns).
+		From(tableName).Where(sq.Eq{"pull_request_id": prID}).
+		Limit(1).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	var pr domain.PullRequest
+	if err = tx.GetContext(ctx, &pr, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fail(domain.NOT_FOUND, "resource not found", err)
+		}
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	pr.AssignedReviewers, pr.FallbackReviewers, err = selectReviewers(ctx, tx, prID)
+	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
@@ -122,202 +184,503 @@ func (r *Repository) FindPullRequestsIDByUserID(ctx context.Context, userID stri
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
-	return result, nil
+	return pr, nil
 }
 
-func (r *Repository) MergePullRequest(ctx context.Context, prID string) (domain.PullRequest, error) {
-	const op = "pull_requests.Repository.MergePullRequest"
+func (r *Repository) FetchByID(ctx context.Context, prID string) (domain.PullRequest, error) {
+	const op = "pull_requests.Repository.FetchByID"
 
 	fail := func(code domain.ErrorCode, message string, err error) (domain.PullRequest, error) {
 		log.Printf("%s: %v\n", op, err)
 		return domain.PullRequest{}, domain.NewError(code, message, err)
 	}
 
-	tx, err := r.db.BeginTxx(ctx, nil)
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Select("pull_request_id", "pull_request_name", "author_id", "status", "need_more_reviewers", "version",
+		"COALESCE(created_at, '0001-01-01'::timestamp) as created_at", "COALESCE(merged_at, '0001-01-01'::timestamp) as merged_at",
+		"COALESCE(closed_at, '0001-01-01'::timestamp) as closed_at", metadataColumns).
+		From(tableName).Where(sq.Eq{"pull_request_id": prID}).
+		Limit(1).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	var pr domain.PullRequest
+	if err = tx.GetContext(ctx, &pr, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fail(domain.NOT_FOUND, "resource not found", err)
+		}
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	pr.AssignedReviewers, pr.FallbackReviewers, err = selectReviewers(ctx, tx, prID)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return pr, nil
+}
+
+// FetchByIDs returns the pull requests with their reviewers, ordered by
+// pull_request_id. Unknown ids are skipped.
+func (r *Repository) FetchByIDs(ctx context.Context, prIDs []string) ([]domain.PullRequest, error) {
+	const op = "pull_requests.Repository.FetchByIDs"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]domain.PullRequest, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Select("pull_request_id", "pull_request_name", "author_id", "status", "need_more_reviewers", "version",
+		"COALESCE(created_at, '0001-01-01'::timestamp) as created_at", "COALESCE(merged_at, '0001-01-01'::timestamp) as merged_at",
+		"COALESCE(closed_at, '0001-01-01'::timestamp) as closed_at", metadataColumns).
+		From(tableName).Where(sq.Eq{"pull_request_id": prIDs}).
+		OrderBy("pull_request_id").PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	var prs []domain.PullRequest
+	if err = tx.SelectContext(ctx, &prs, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	reviewers, fallbackReviewers, err := selectReviewersByPullRequests(ctx, tx, prIDs)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	for i := range prs {
+		prs[i].AssignedReviewers = reviewers[prs[i].PullRequestID]
+		prs[i].FallbackReviewers = fallbackReviewers[prs[i].PullRequestID]
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return prs, nil
+}
+
+func (r *Repository) SetNeedMoreReviewers(ctx context.Context, prID string, needMoreReviewers bool) error {
+	const op = "pull_requests.Repository.SetNeedMoreReviewers"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	defer func(tx *sqlx.Tx) {
+	defer func(tx *transaction.Tx) {
 		_ = tx.Rollback()
 	}(tx)
 
 	query, args, err := sq.Update(tableName).
-		Set("status", "MERGED").
-		Set("merged_at", time.Now()).
+		Set("need_more_reviewers", needMoreReviewers).
 		Where(sq.Eq{"pull_request_id": prID}).
-		Suffix("RETURNING pull_request_id, pull_request_name, author_id, status, merged_at").
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
-	var pr domain.PullRequest
-	if err = tx.GetContext(ctx, &pr, query, args...); err != nil {
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
-	var reviewers []string
-	query, args, err = sq.Select("reviewer_id").
-		From(reviewersTableName).
-		Where(sq.Eq{"pull_request_id": prID}).
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
+}
+
+// SetNeedMoreReviewersByIDs stores need_more_reviewers of several pull requests
+// with one statement.
+func (r *Repository) SetNeedMoreReviewersByIDs(ctx context.Context, needMoreReviewers map[string]bool) error {
+	const op = "pull_requests.Repository.SetNeedMoreReviewersByIDs"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	if len(needMoreReviewers) == 0 {
+		return nil
+	}
+
+	prIDs := make([]string, 0, len(needMoreReviewers))
+	understaffed := make([]string, 0, len(needMoreReviewers))
+	for prID, need := range needMoreReviewers {
+		prIDs = append(prIDs, prID)
+		if need {
+			understaffed = append(understaffed, prID)
+		}
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Update(tableName).
+		Set("need_more_reviewers", sq.Expr("pull_request_id = ANY(?)", pq.Array(understaffed))).
+		Where(sq.Eq{"pull_request_id": prIDs}).
+		Where("need_more_reviewers IS DISTINCT FROM (pull_request_id = ANY(?))", pq.Array(understaffed)).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
-	if err = tx.SelectContext(ctx, &reviewers, query, args...); err != nil {
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
-	updated := domain.PullRequest{
-		PullRequestID:     pr.PullRequestID,
-		PullRequestName:   pr.PullRequestName,
-		AuthorID:          pr.AuthorID,
-		Status:            pr.Status,
-		AssignedReviewers: reviewers,
-		MergedAt:          pr.MergedAt,
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
+}
+
+// RefreshNeedMoreReviewers recomputes need_more_reviewers of OPEN pull requests
+// authored by members of the team, or of all of them if teamName is empty, from
+// their current reviewers and the required_reviewers of the author's team.
+func (r *Repository) RefreshNeedMoreReviewers(ctx context.Context, teamName string) error {
+	const op = "pull_requests.Repository.RefreshNeedMoreReviewers"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	const understaffed = "(SELECT COUNT(*) FROM " + reviewersTableName + " prr WHERE prr.pull_request_id = pr.pull_request_id) < t.required_reviewers"
+
+	builder := sq.Update(tableName+" pr").
+		Set("need_more_reviewers", sq.Expr(understaffed)).
+		From("users au JOIN teams t ON t.team_name = au.team_name").
+		Where("au.user_id = pr.author_id").
+		Where(sq.Eq{"pr.status": domain.OPEN}).
+		Where("pr.need_more_reviewers IS DISTINCT FROM (" + understaffed + ")")
+	if teamName != "" {
+		builder = builder.Where(sq.Eq{"au.team_name": teamName})
+	}
+
+	query, args, err := builder.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
 	if err = tx.Commit(); err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
-	return updated, nil
+	return nil
 }
 
-func (r *Repository) FetchByIDWithMergeAt(ctx context.Context, prID string) (domain.PullRequest, error) {
-	const op = "pull_requests.Repository.FetchByIDWithMergedAt"
+// UpdatePullRequest stores the name, author and metadata of the pull request.
+func (r *Repository) UpdatePullRequest(ctx context.Context, pr *domain.PullRequest) error {
+	const op = "pull_requests.Repository.UpdatePullRequest"
 
-	fail := func(code domain.ErrorCode, message string, err error) (domain.PullRequest, error) {
+	fail := func(code domain.ErrorCode, message string, err error) error {
 		log.Printf("%s: %v\n", op, err)
-		return domain.PullRequest{}, domain.NewError(code, message, err)
+		return domain.NewError(code, message, err)
 	}
 
-	tx, err := r.db.BeginTxx(ctx, nil)
+	tx, err := transaction.Begin(ctx, r.db)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	defer func(tx *sqlx.Tx) {
+	defer func(tx *transaction.Tx) {
 		_ = tx.Rollback()
 	}(tx)
 
-	query, args, err := sq.Select("pull_request_id", "pull_request_name", "author_id", "status", "COALESCE(merged_at, '0001-01-01'::timestamp) as merged_at").
-		From(tableName).Where(sq.Eq{"pull_request_id": prID}).
-		Limit(1).PlaceholderFormat(sq.Dollar).ToSql()
+	query, args, err := sq.Update(tableName).
+		Set("pull_request_name", pr.PullRequestName).
+		Set("author_id", pr.AuthorID).
+		Set("url", pr.URL).
+		Set("repository", pr.Repository).
+		Set("source_branch", pr.SourceBranch).
+		Set("target_branch", pr.TargetBranch).
+		Set("description", pr.Description).
+		Where(sq.Eq{"pull_request_id": pr.PullRequestID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
-	var pr domain.PullRequest
-	if err = tx.GetContext(ctx, &pr, query, args...); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return fail(domain.NOT_FOUND, "resource not found", err)
-		}
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
+	if affected == 0 {
+		return fail(domain.NOT_FOUND, "resource not found", nil)
+	}
 
-	var reviewers []string
-	query, args, err = sq.Select("reviewer_id").
-		From(reviewersTableName).
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
+}
+
+// UpdateStatus moves the pull request to the given status. closed_at is set when
+// the pull request is closed and cleared otherwise.
+func (r *Repository) UpdateStatus(ctx context.Context, prID string, status domain.Status) error {
+	const op = "pull_requests.Repository.UpdateStatus"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	var closedAt *time.Time
+	if status == domain.CLOSED {
+		now := time.Now()
+		closedAt = &now
+	}
+
+	query, args, err := sq.Update(tableName).
+		Set("status", status).
+		Set("closed_at", closedAt).
 		Where(sq.Eq{"pull_request_id": prID}).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	if err = tx.SelectContext(ctx, &reviewers, query, args...); err != nil {
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
-	pr.AssignedReviewers = reviewers
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	if affected == 0 {
+		return fail(domain.NOT_FOUND, "resource not found", nil)
+	}
 
 	if err = tx.Commit(); err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
-	return pr, nil
+	return nil
 }
 
-func (r *Repository) FetchByID(ctx context.Context, prID string) (domain.PullRequest, error) {
-	const op = "pull_requests.Repository.FetchByID"
+// FindActiveIDsByAuthors returns OPEN and DRAFT pull requests of the authors.
+func (r *Repository) FindActiveIDsByAuthors(ctx context.Context, authorIDs []string) ([]string, error) {
+	const op = "pull_requests.Repository.FindActiveIDsByAuthors"
 
-	fail := func(code domain.ErrorCode, message string, err error) (domain.PullRequest, error) {
+	fail := func(code domain.ErrorCode, message string, err error) ([]string, error) {
 		log.Printf("%s: %v\n", op, err)
-		return domain.PullRequest{}, domain.NewError(code, message, err)
+		return nil, domain.NewError(code, message, err)
 	}
 
-	tx, err := r.db.BeginTxx(ctx, nil)
+	tx, err := transaction.Begin(ctx, r.db)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	defer func(tx *sqlx.Tx) {
+	defer func(tx *transaction.Tx) {
 		_ = tx.Rollback()
 	}(tx)
 
-	query, args, err := sq.Select("pull_request_id", "pull_request_name", "author_id", "status").
-		From(tableName).Where(sq.Eq{"pull_request_id": prID}).
-		Limit(1).PlaceholderFormat(sq.Dollar).ToSql()
+	query, args, err := sq.Select("pull_request_id").
+		From(tableName).
+		Where(sq.Eq{"author_id": authorIDs, "status": []domain.Status{domain.OPEN, domain.DRAFT}}).
+		OrderBy("pull_request_id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
-	var pr domain.PullRequest
-	if err = tx.GetContext(ctx, &pr, query, args...); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return fail(domain.NOT_FOUND, "resource not found", err)
-		}
+	result := []string{}
+	if err = tx.SelectContext(ctx, &result, query, args...); err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
-	var reviewers []string
-	query, args, err = sq.Select("reviewer_id").
-		From(reviewersTableName).
-		Where(sq.Eq{"pull_request_id": prID}).
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return result, nil
+}
+
+// FindIDsByAuthorTeams returns pull requests of any status attributed to the
+// teams, the ones a rename or deletion of the teams updates.
+func (r *Repository) FindIDsByAuthorTeams(ctx context.Context, teamNames []string) ([]string, error) {
+	const op = "pull_requests.Repository.FindIDsByAuthorTeams"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]string, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Select("pull_request_id").
+		From(tableName).
+		Where(sq.Eq{"author_team_name": teamNames}).
+		OrderBy("pull_request_id").
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	if err = tx.SelectContext(ctx, &reviewers, query, args...); err != nil {
+
+	result := []string{}
+	if err = tx.SelectContext(ctx, &result, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return result, nil
+}
+
+// FindUnderstaffedIDs returns OPEN pull requests marked with need_more_reviewers.
+// When teamName is set, only PRs which may take reviewers from this team are
+// returned: authored by its members or by members of teams using it as a fallback.
+func (r *Repository) FindUnderstaffedIDs(ctx context.Context, teamName string) ([]string, error) {
+	const op = "pull_requests.Repository.FindUnderstaffedIDs"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]string, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
 
-	pr.AssignedReviewers = reviewers
+	builder := sq.Select("pr.pull_request_id").
+		From(tableName+" pr").
+		Where(sq.Eq{"pr.status": domain.OPEN, "pr.need_more_reviewers": true}).
+		OrderBy("pr.created_at", "pr.pull_request_id")
+	if teamName != "" {
+		builder = builder.
+			Join("users au ON au.user_id = pr.author_id").
+			Where(sq.Or{
+				sq.Eq{"au.team_name": teamName},
+				sq.Expr("au.team_name IN (SELECT team_name FROM team_fallbacks WHERE fallback_team_name = ?)", teamName),
+			})
+	}
+
+	query, args, err := builder.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	var result []string
+	if err = tx.SelectContext(ctx, &result, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
 
 	if err = tx.Commit(); err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
-	return pr, nil
+	return result, nil
 }
 
-func (r *Repository) FetchShortByID(ctx context.Context, prID string) (domain.PullRequestShort, error) {
-	const op = "pull_requests.Repository.FetchShortByID"
+// LockPullRequests locks the pull requests in pull_request_id order, so that two
+// batches never wait for each other crosswise.
+func (r *Repository) LockPullRequests(ctx context.Context, prIDs []string) error {
+	const op = "pull_requests.Repository.LockPullRequests"
 
-	fail := func(code domain.ErrorCode, message string, err error) (domain.PullRequestShort, error) {
+	fail := func(code domain.ErrorCode, message string, err error) error {
 		log.Printf("%s: %v\n", op, err)
-		return domain.PullRequestShort{}, domain.NewError(code, message, err)
+		return domain.NewError(code, message, err)
 	}
 
-	tx, err := r.db.BeginTxx(ctx, nil)
+	tx, err := transaction.Begin(ctx, r.db)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	defer func(tx *sqlx.Tx) {
+	defer func(tx *transaction.Tx) {
 		_ = tx.Rollback()
 	}(tx)
 
-	query, args, err := sq.Select(
-		"pull_request_id", "pull_request_name", "author_id", "status",
-	).From(tableName).Where(sq.Eq{"pull_request_id": prID}).PlaceholderFormat(sq.Dollar).ToSql()
+	query, args, err := sq.Select("pull_request_id").
+		From(tableName).
+		Where(sq.Eq{"pull_request_id": prIDs}).
+		OrderBy("pull_request_id").
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
-	var pr domain.PullRequestShort
-	if err = tx.GetContext(ctx, &pr, query, args...); err != nil {
+	var locked []string
+	if err = tx.SelectContext(ctx, &locked, query, args...); err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
@@ -325,5 +688,48 @@ func (r *Repository) FetchShortByID(ctx context.Context, prID string) (domain.Pu
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
-	return pr, nil
+	return nil
+}
+
+// LockPullRequest locks the pull request row until the surrounding transaction ends, so it only
+// has an effect inside transaction.Manager.WithinTransaction.
+func (r *Repository) LockPullRequest(ctx context.Context, prID string) error {
+	const op = "pull_requests.Repository.LockPullRequest"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Select("pull_request_id").
+		From(tableName).
+		Where(sq.Eq{"pull_request_id": prID}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	var locked string
+	if err = tx.GetContext(ctx, &locked, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fail(domain.NOT_FOUND, "resource not found", err)
+		}
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
 }
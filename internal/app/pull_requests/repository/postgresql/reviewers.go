@@ -7,6 +7,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
 	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/transaction"
 )
 
 const reviewersTableName = "pull_request_reviewers"
@@ -19,11 +20,11 @@ func (r *Repository) GetReviewersID(ctx context.Context, prID string) ([]string,
 		return nil, domain.NewError(code, message, err)
 	}
 
-	tx, err := r.db.BeginTxx(ctx, nil)
+	tx, err := transaction.Begin(ctx, r.db)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	defer func(tx *sqlx.Tx) {
+	defer func(tx *transaction.Tx) {
 		_ = tx.Rollback()
 	}(tx)
 
@@ -67,11 +68,11 @@ func (r *Repository) DeleteReviewer(ctx context.Context, prID, reviewerID string
 		return domain.NewError(code, message, err)
 	}
 
-	tx, err := r.db.BeginTxx(ctx, nil)
+	tx, err := transaction.Begin(ctx, r.db)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	defer func(tx *sqlx.Tx) {
+	defer func(tx *transaction.Tx) {
 		_ = tx.Rollback()
 	}(tx)
 
@@ -99,11 +100,11 @@ func (r *Repository) AddReviewer(ctx context.Context, prID, reviewerID string) e
 		return domain.NewError(code, message, err)
 	}
 
-	tx, err := r.db.BeginTxx(ctx, nil)
+	tx, err := transaction.Begin(ctx, r.db)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	defer func(tx *sqlx.Tx) {
+	defer func(tx *transaction.Tx) {
 		_ = tx.Rollback()
 	}(tx)
 
@@ -122,3 +123,250 @@ func (r *Repository) AddReviewer(ctx context.Context, prID, reviewerID string) e
 
 	return nil
 }
+
+// AddReviewers assigns the reviewers, keyed by pull_request_id, with one statement.
+func (r *Repository) AddReviewers(ctx context.Context, reviewers map[string][]string) error {
+	const op = "pull_requests.Repository.AddReviewers"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	builder := sq.Insert(reviewersTableName).Columns("pull_request_id", "reviewer_id")
+	rowsCount := 0
+	for prID, reviewerIDs := range reviewers {
+		for _, reviewerID := range reviewerIDs {
+			builder = builder.Values(prID, reviewerID)
+			rowsCount++
+		}
+	}
+	if rowsCount == 0 {
+		return nil
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := builder.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
+}
+
+// ReleaseOpenReviews removes the users from the reviewers of all OPEN pull requests
+// and returns the removed reviewers grouped by pull request.
+func (r *Repository) ReleaseOpenReviews(ctx context.Context, reviewerIDs []string) (map[string][]string, error) {
+	const op = "pull_requests.Repository.ReleaseOpenReviews"
+
+	fail := func(code domain.ErrorCode, message string, err error) (map[string][]string, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Delete(reviewersTableName).
+		Where(sq.Eq{"reviewer_id": reviewerIDs}).
+		Where("pull_request_id IN (SELECT pull_request_id FROM "+tableName+" WHERE status = ?)", domain.OPEN).
+		Suffix("RETURNING pull_request_id, reviewer_id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	rows, err := tx.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(rows *sqlx.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	released := make(map[string][]string)
+	for rows.Next() {
+		var prID, reviewerID string
+		if err = rows.Scan(&prID, &reviewerID); err != nil {
+			return fail(domain.INTERNAL, "internal server error", err)
+		}
+		released[prID] = append(released[prID], reviewerID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = rows.Close(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return released, nil
+}
+
+// FindForeignReviewIDs returns OPEN pull requests reviewed by the user although
+// the user has left the team the review was assigned for and the current team is
+// neither the author's team nor one of its fallback teams.
+func (r *Repository) FindForeignReviewIDs(ctx context.Context, reviewerID string) ([]string, error) {
+	const op = "pull_requests.Repository.FindForeignReviewIDs"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]string, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Select("prr.pull_request_id").
+		From(reviewersTableName + " prr").
+		Join(tableName + " pr ON pr.pull_request_id = prr.pull_request_id").
+		Join("users ru ON ru.user_id = prr.reviewer_id").
+		Join("users au ON au.user_id = pr.author_id").
+		Where(sq.Eq{"prr.reviewer_id": reviewerID, "pr.status": domain.OPEN}).
+		Where("prr.reviewer_team_name IS DISTINCT FROM ru.team_name").
+		Where("ru.team_name IS DISTINCT FROM au.team_name").
+		Where("NOT EXISTS (SELECT 1 FROM team_fallbacks tf WHERE tf.team_name = au.team_name AND tf.fallback_team_name = ru.team_name)").
+		OrderBy("prr.pull_request_id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	result := []string{}
+	if err = tx.SelectContext(ctx, &result, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return result, nil
+}
+
+// FindOpenReviewIDs returns OPEN pull requests reviewed by any of the users.
+func (r *Repository) FindOpenReviewIDs(ctx context.Context, reviewerIDs []string) ([]string, error) {
+	const op = "pull_requests.Repository.FindOpenReviewIDs"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]string, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Select("DISTINCT prr.pull_request_id").
+		From(reviewersTableName + " prr").
+		Join(tableName + " pr ON pr.pull_request_id = prr.pull_request_id").
+		Where(sq.Eq{"prr.reviewer_id": reviewerIDs, "pr.status": domain.OPEN}).
+		OrderBy("prr.pull_request_id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	result := []string{}
+	if err = tx.SelectContext(ctx, &result, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return result, nil
+}
+
+// selectReviewers returns reviewers of the PR and those of them who were not
+// in the author's team when assigned, i.e. were taken from a fallback team.
+func selectReviewers(ctx context.Context, tx *transaction.Tx, prID string) ([]string, []string, error) {
+	reviewers, fallbackReviewers, err := selectReviewersByPullRequests(ctx, tx, []string{prID})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return reviewers[prID], fallbackReviewers[prID], nil
+}
+
+// selectReviewersByPullRequests does the same as selectReviewers for several pull
+// requests at once, keyed by pull_request_id.
+func selectReviewersByPullRequests(ctx context.Context, tx *transaction.Tx, prIDs []string) (map[string][]string, map[string][]string, error) {
+	reviewers := make(map[string][]string)
+	fallbackReviewers := make(map[string][]string)
+	if len(prIDs) == 0 {
+		return reviewers, fallbackReviewers, nil
+	}
+
+	query, args, err := sq.Select("prr.pull_request_id", "prr.reviewer_id", "prr.reviewer_team_name IS DISTINCT FROM au.team_name").
+		From(reviewersTableName + " prr").
+		Join(tableName + " pr ON pr.pull_request_id = prr.pull_request_id").
+		Join("users au ON au.user_id = pr.author_id").
+		Where(sq.Eq{"prr.pull_request_id": prIDs}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := tx.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func(rows *sqlx.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	for rows.Next() {
+		var prID, reviewerID string
+		var fromFallback bool
+		if err = rows.Scan(&prID, &reviewerID, &fromFallback); err != nil {
+			return nil, nil, err
+		}
+
+		reviewers[prID] = append(reviewers[prID], reviewerID)
+		if fromFallback {
+			fallbackReviewers[prID] = append(fallbackReviewers[prID], reviewerID)
+		}
+	}
+
+	return reviewers, fallbackReviewers, rows.Err()
+}
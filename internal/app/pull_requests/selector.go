@@ -0,0 +1,14 @@
+package pull_requests
+
+import (
+	"context"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+)
+
+// ReviewerSelector picks up to count reviewers out of already filtered candidates.
+type ReviewerSelector interface {
+	Select(ctx context.Context, teamName string, candidates []domain.Candidate, count int) ([]string, error)
+	// Peek answers like Select but leaves the selector state untouched.
+	Peek(ctx context.Context, teamName string, candidates []domain.Candidate, count int) ([]string, error)
+}
@@ -0,0 +1,50 @@
+package selector
+
+import (
+	"cmp"
+	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+	"sync"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+)
+
+// draws keeps a draw counter per team. The candidates are shuffled with a seed
+// built from a random per-process base, the team and its counter, so Peek sees
+// exactly the order the next Select will use as long as the candidates stay the
+// same. Like the round robin cursor the counters live in memory and start over
+// after a restart, the base makes sure the draws do not repeat with them.
+type draws struct {
+	mu     sync.Mutex
+	base   uint64
+	counts map[string]uint64
+}
+
+func newDraws() *draws {
+	return &draws{base: rand.Uint64(), counts: make(map[string]uint64)}
+}
+
+func (d *draws) shuffle(teamName string, candidates []domain.Candidate, advance bool) []domain.Candidate {
+	d.mu.Lock()
+	draw := d.counts[teamName]
+	if advance {
+		d.counts[teamName] = draw + 1
+	}
+	d.mu.Unlock()
+
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(teamName))
+
+	shuffled := slices.Clone(candidates)
+	slices.SortFunc(shuffled, func(a, b domain.Candidate) int {
+		return cmp.Compare(a.UserID, b.UserID)
+	})
+
+	rnd := rand.New(rand.NewPCG(d.base^hash.Sum64(), draw))
+	rnd.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	return shuffled
+}
@@ -0,0 +1,35 @@
+package selector
+
+import (
+	"context"
+	"slices"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+)
+
+// LeastLoadedSelector prefers candidates with the fewest OPEN reviews,
+// ties are broken by the random order drawn per team.
+type LeastLoadedSelector struct {
+	draws *draws
+}
+
+func NewLeastLoadedSelector() *LeastLoadedSelector {
+	return &LeastLoadedSelector{draws: newDraws()}
+}
+
+func (s *LeastLoadedSelector) Select(_ context.Context, teamName string, candidates []domain.Candidate, count int) ([]string, error) {
+	return s.pick(teamName, candidates, count, true), nil
+}
+
+func (s *LeastLoadedSelector) Peek(_ context.Context, teamName string, candidates []domain.Candidate, count int) ([]string, error) {
+	return s.pick(teamName, candidates, count, false), nil
+}
+
+func (s *LeastLoadedSelector) pick(teamName string, candidates []domain.Candidate, count int, advance bool) []string {
+	sorted := s.draws.shuffle(teamName, candidates, advance)
+	slices.SortStableFunc(sorted, func(a, b domain.Candidate) int {
+		return a.OpenReviews - b.OpenReviews
+	})
+
+	return candidateIDs(sorted[:min(count, len(sorted))])
+}
@@ -0,0 +1,29 @@
+package selector
+
+import (
+	"context"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+)
+
+// RandomSelector picks candidates in a random order drawn per team.
+type RandomSelector struct {
+	draws *draws
+}
+
+func NewRandomSelector() *RandomSelector {
+	return &RandomSelector{draws: newDraws()}
+}
+
+func (s *RandomSelector) Select(_ context.Context, teamName string, candidates []domain.Candidate, count int) ([]string, error) {
+	return s.pick(teamName, candidates, count, true), nil
+}
+
+func (s *RandomSelector) Peek(_ context.Context, teamName string, candidates []domain.Candidate, count int) ([]string, error) {
+	return s.pick(teamName, candidates, count, false), nil
+}
+
+func (s *RandomSelector) pick(teamName string, candidates []domain.Candidate, count int, advance bool) []string {
+	shuffled := s.draws.shuffle(teamName, candidates, advance)
+	return candidateIDs(shuffled[:min(count, len(shuffled))])
+}
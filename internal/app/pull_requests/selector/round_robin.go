@@ -0,0 +1,49 @@
+package selector
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+)
+
+// RoundRobinSelector rotates over the sorted candidates, keeping a cursor per team.
+// The cursor lives in memory, so it starts over after a restart.
+type RoundRobinSelector struct {
+	mu      sync.Mutex
+	cursors map[string]int
+}
+
+func NewRoundRobinSelector() *RoundRobinSelector {
+	return &RoundRobinSelector{cursors: make(map[string]int)}
+}
+
+func (s *RoundRobinSelector) Select(_ context.Context, teamName string, candidates []domain.Candidate, count int) ([]string, error) {
+	return s.pick(teamName, candidates, count, true), nil
+}
+
+func (s *RoundRobinSelector) Peek(_ context.Context, teamName string, candidates []domain.Candidate, count int) ([]string, error) {
+	return s.pick(teamName, candidates, count, false), nil
+}
+
+func (s *RoundRobinSelector) pick(teamName string, candidates []domain.Candidate, count int, advance bool) []string {
+	sorted := candidateIDs(candidates)
+	slices.Sort(sorted)
+
+	count = min(count, len(sorted))
+
+	s.mu.Lock()
+	start := s.cursors[teamName] % len(sorted)
+	if advance {
+		s.cursors[teamName] = start + count
+	}
+	s.mu.Unlock()
+
+	result := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		result = append(result, sorted[(start+i)%len(sorted)])
+	}
+
+	return result
+}
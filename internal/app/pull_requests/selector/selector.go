@@ -0,0 +1,77 @@
+package selector
+
+import (
+	"context"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/app/pull_requests"
+	"github.com/leoscrowi/pr-assignment-service/internal/config"
+)
+
+type Strategy string
+
+const (
+	Random      Strategy = "random"
+	RoundRobin  Strategy = "round_robin"
+	LeastLoaded Strategy = "least_loaded"
+)
+
+// Selector dispatches selection to the strategy configured for the team,
+// falling back to the global one.
+type Selector struct {
+	defaultSelector pull_requests.ReviewerSelector
+	teamSelectors   map[string]pull_requests.ReviewerSelector
+}
+
+func NewSelector(cfg config.AssignmentConfig) *Selector {
+	build := func(strategy string) pull_requests.ReviewerSelector {
+		switch Strategy(strategy) {
+		case Random:
+			return NewRandomSelector()
+		case RoundRobin:
+			return NewRoundRobinSelector()
+		default:
+			return NewLeastLoadedSelector()
+		}
+	}
+
+	teamSelectors := make(map[string]pull_requests.ReviewerSelector, len(cfg.TeamStrategies))
+	for teamName, strategy := range cfg.TeamStrategies {
+		teamSelectors[teamName] = build(strategy)
+	}
+
+	return &Selector{
+		defaultSelector: build(cfg.Strategy),
+		teamSelectors:   teamSelectors,
+	}
+}
+
+func (s *Selector) Select(ctx context.Context, teamName string, candidates []domain.Candidate, count int) ([]string, error) {
+	if count <= 0 || len(candidates) == 0 {
+		return []string{}, nil
+	}
+
+	if sel, ok := s.teamSelectors[teamName]; ok {
+		return sel.Select(ctx, teamName, candidates, count)
+	}
+	return s.defaultSelector.Select(ctx, teamName, candidates, count)
+}
+
+func candidateIDs(candidates []domain.Candidate) []string {
+	ids := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		ids = append(ids, candidate.UserID)
+	}
+	return ids
+}
+
+func (s *Selector) Peek(ctx context.Context, teamName string, candidates []domain.Candidate, count int) ([]string, error) {
+	if count <= 0 || len(candidates) == 0 {
+		return []string{}, nil
+	}
+
+	if sel, ok := s.teamSelectors[teamName]; ok {
+		return sel.Peek(ctx, teamName, candidates, count)
+	}
+	return s.defaultSelector.Peek(ctx, teamName, candidates, count)
+}
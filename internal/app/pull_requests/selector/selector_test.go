@@ -0,0 +1,118 @@
+package selector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func candidates(loads ...int) []domain.Candidate {
+	ids := []string{"u1", "u2", "u3", "u4"}
+	result := make([]domain.Candidate, 0, len(loads))
+	for i, load := range loads {
+		result = append(result, domain.Candidate{UserID: ids[i], OpenReviews: load})
+	}
+	return result
+}
+
+func TestRandomSelector_ReturnsDistinctCandidates(t *testing.T) {
+	got, err := NewRandomSelector().Select(context.Background(), "team", candidates(0, 0, 0), 2)
+	require.NoError(t, err)
+
+	assert.Len(t, got, 2)
+	assert.NotEqual(t, got[0], got[1])
+	assert.Subset(t, []string{"u1", "u2", "u3"}, got)
+}
+
+func TestRoundRobinSelector_RotatesPerTeam(t *testing.T) {
+	s := NewRoundRobinSelector()
+	pool := []domain.Candidate{{UserID: "u3"}, {UserID: "u1"}, {UserID: "u2"}}
+
+	first, err := s.Select(context.Background(), "team", pool, 2)
+	require.NoError(t, err)
+	second, err := s.Select(context.Background(), "team", pool, 2)
+	require.NoError(t, err)
+	other, err := s.Select(context.Background(), "other", pool, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"u1", "u2"}, first)
+	assert.Equal(t, []string{"u3", "u1"}, second)
+	assert.Equal(t, []string{"u1"}, other)
+}
+
+func TestRoundRobinSelector_PeekKeepsCursor(t *testing.T) {
+	s := NewRoundRobinSelector()
+	pool := []domain.Candidate{{UserID: "u1"}, {UserID: "u2"}, {UserID: "u3"}}
+
+	peeked, err := s.Peek(context.Background(), "team", pool, 2)
+	require.NoError(t, err)
+	selected, err := s.Select(context.Background(), "team", pool, 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, peeked, selected)
+}
+
+func TestLeastLoadedSelector_PrefersFewestOpenReviews(t *testing.T) {
+	got, err := NewLeastLoadedSelector().Select(context.Background(), "team", candidates(3, 0, 1), 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"u2", "u3"}, got)
+}
+
+func TestLeastLoadedSelector_BreaksTiesRandomly(t *testing.T) {
+	s := NewLeastLoadedSelector()
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		got, err := s.Select(context.Background(), "team", candidates(1, 1, 1, 5), 1)
+		require.NoError(t, err)
+		seen[got[0]] = true
+	}
+
+	assert.Equal(t, map[string]bool{"u1": true, "u2": true, "u3": true}, seen)
+}
+
+func TestSelector_UsesTeamStrategy(t *testing.T) {
+	s := NewSelector(config.AssignmentConfig{
+		Strategy:       string(Random),
+		TeamStrategies: map[string]string{"backend": string(LeastLoaded)},
+	})
+
+	got, err := s.Select(context.Background(), "backend", candidates(5, 0), 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2"}, got)
+
+	got, err = s.Select(context.Background(), "frontend", nil, 2)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestRandomSelector_PeekMatchesNextSelect(t *testing.T) {
+	s := NewRandomSelector()
+
+	for i := 0; i < 20; i++ {
+		peeked, err := s.Peek(context.Background(), "team", candidates(0, 0, 0, 0), 2)
+		require.NoError(t, err)
+		selected, err := s.Select(context.Background(), "team", candidates(0, 0, 0, 0), 2)
+		require.NoError(t, err)
+
+		assert.Equal(t, peeked, selected)
+	}
+}
+
+func TestLeastLoadedSelector_PeekMatchesNextSelect(t *testing.T) {
+	s := NewLeastLoadedSelector()
+
+	for i := 0; i < 20; i++ {
+		peeked, err := s.Peek(context.Background(), "team", candidates(1, 1, 1, 5), 2)
+		require.NoError(t, err)
+		selected, err := s.Select(context.Background(), "team", candidates(1, 1, 1, 5), 2)
+		require.NoError(t, err)
+
+		assert.Equal(t, peeked, selected)
+	}
+}
@@ -7,7 +7,53 @@ import (
 )
 
 type Usecase interface {
-	ReassignPullRequest(ctx context.Context, pullRequestID string, oldUserID string) (domain.PullRequest, string, error)
-	MergePullRequest(ctx context.Context, pullRequestID string) (domain.PullRequest, error)
+	ReassignPullRequest(ctx context.Context, pullRequestID string, oldUserID string, newUserID string, expectedVersion *int64) (domain.PullRequest, string, error)
+	AddReviewer(ctx context.Context, pullRequestID string, userID string, expectedVersion *int64) (domain.PullRequest, error)
+	RemoveReviewer(ctx context.Context, pullRequestID string, userID string, expectedVersion *int64) (domain.PullRequest, error)
+	MergePullRequest(ctx context.Context, pullRequestID string, expectedVersion *int64) (domain.PullRequest, error)
+	UpdatePullRequest(ctx context.Context, pullRequestID string, update domain.PullRequestUpdate, expectedVersion *int64) (domain.PullRequest, error)
+	ClosePullRequest(ctx context.Context, pullRequestID string, expectedVersion *int64) (domain.PullRequest, error)
+	MarkReady(ctx context.Context, pullRequestID string, expectedVersion *int64) (domain.PullRequest, error)
+	ReopenPullRequest(ctx context.Context, pullRequestID string, expectedVersion *int64) (domain.PullRequest, error)
 	CreatePullRequest(ctx context.Context, pullRequest *domain.PullRequest) (domain.PullRequest, error)
+	PreviewAssignment(ctx context.Context, authorID string) (domain.AssignmentPreview, error)
+	GetPullRequest(ctx context.Context, pullRequestID string) (domain.PullRequest, error)
+	ListPullRequests(ctx context.Context, filter domain.PullRequestFilter) (domain.PullRequestPage, error)
+	Backfiller
+	ReviewerReleaser
+	AuthorReleaser
+	Locker
+}
+
+// Backfiller tops up OPEN pull requests that still need more reviewers.
+type Backfiller interface {
+	BackfillPullRequests(ctx context.Context, teamName string) ([]domain.BackfillResult, error)
+}
+
+// ReviewerReleaser reassigns OPEN reviews of users who can no longer do them.
+type ReviewerReleaser interface {
+	ReleaseReviewers(ctx context.Context, userIDs []string) ([]domain.ReassignmentResult, error)
+	ReleaseForeignReviews(ctx context.Context, userID string) ([]domain.ReassignmentResult, error)
+}
+
+// LockScope lists what a change of users or teams is going to touch.
+type LockScope struct {
+	// UserIDs are users whose OPEN and DRAFT pull requests and OPEN reviews change.
+	UserIDs []string
+	// AuthorTeams are teams being renamed or deleted, which updates every pull
+	// request attributed to them.
+	AuthorTeams []string
+	// TeamNames are locked together with the teams reviewers are picked from.
+	TeamNames []string
+}
+
+// Locker takes the pull request and team locks a change of users or teams needs,
+// in the order every writer takes them: pull requests before teams.
+type Locker interface {
+	LockForChange(ctx context.Context, scope LockScope) error
+}
+
+// AuthorReleaser closes OPEN and DRAFT pull requests of authors who leave their team.
+type AuthorReleaser interface {
+	CloseAuthoredPullRequests(ctx context.Context, authorIDs []string) ([]string, error)
 }
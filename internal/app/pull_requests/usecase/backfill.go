@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"slices"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+)
+
+// BackfillPullRequests assigns missing reviewers to OPEN pull requests marked
+// with need_more_reviewers and reports the ones which got new reviewers. The flag
+// of the team's pull requests is recomputed first, since required_reviewers or
+// the author's team may have changed since it was set. A pull request which
+// fails to backfill is logged and skipped, the ones already committed are still
+// reported.
+func (u *usecase) BackfillPullRequests(ctx context.Context, teamName string) ([]domain.BackfillResult, error) {
+	const op = "pull_request.Usecase.BackfillPullRequests"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]domain.BackfillResult, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	if err := u.PullRequestRepository.RefreshNeedMoreReviewers(ctx, teamName); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	prIDs, err := u.PullRequestRepository.FindUnderstaffedIDs(ctx, teamName)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	result := []domain.BackfillResult{}
+	for _, prID := range prIDs {
+		var filled domain.BackfillResult
+		err = u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			filled, err = u.backfillPullRequest(ctx, prID)
+			return err
+		})
+		if err != nil {
+			log.Printf("%s: %s: %v\n", op, prID, err)
+			continue
+		}
+
+		if len(filled.AddedReviewers) > 0 {
+			result = append(result, filled)
+		}
+	}
+
+	return result, nil
+}
+
+func (u *usecase) backfillPullRequest(ctx context.Context, prID string) (domain.BackfillResult, error) {
+	filled, err := u.backfillPullRequests(ctx, []string{prID})
+	if err != nil {
+		return domain.BackfillResult{}, err
+	}
+
+	return filled[prID], nil
+}
+
+// backfillPullRequests fills the missing reviewers of several pull requests at
+// once: they are locked, read and updated with one statement each, candidates
+// are read once per team and the reviews assigned earlier in the batch count
+// towards capacity. The ids are collected without locks, so pull requests which
+// are no longer OPEN once locked are skipped. Results are keyed by
+// pull_request_id.
+func (u *usecase) backfillPullRequests(ctx context.Context, prIDs []string) (map[string]domain.BackfillResult, error) {
+	result := make(map[string]domain.BackfillResult, len(prIDs))
+	if len(prIDs) == 0 {
+		return result, nil
+	}
+
+	if err := u.PullRequestRepository.LockPullRequests(ctx, prIDs); err != nil {
+		return nil, err
+	}
+
+	prs, err := u.PullRequestRepository.FetchByIDs(ctx, prIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every team candidates are read from is locked, in one go after the pull
+	// requests; the caller may hold some of them already.
+	teamNames, err := u.selectionTeams(ctx, prs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = u.TeamsRepository.LockTeams(ctx, teamNames); err != nil {
+		return nil, err
+	}
+
+	pool := u.newCandidatePool()
+	authorTeams := make(map[string]domain.Team)
+	added := make(map[string][]string, len(prs))
+	needMoreReviewers := make(map[string]bool, len(prs))
+
+	for _, pr := range prs {
+		if pr.Status != domain.OPEN {
+			continue
+		}
+
+		team, ok := authorTeams[pr.AuthorID]
+		if !ok {
+			if team, err = u.authorTeam(ctx, pr.AuthorID); err != nil {
+				return nil, err
+			}
+			authorTeams[pr.AuthorID] = team
+		}
+
+		exclude := authorExclusion(pr.AuthorID)
+		for _, reviewerID := range pr.AssignedReviewers {
+			exclude[reviewerID] = domain.REJECTED_ALREADY_ASSIGNED
+		}
+
+		selection, err := u.pickReviewersFrom(ctx, pool, team, exclude, team.RequiredReviewers-len(pr.AssignedReviewers), false)
+		if err != nil {
+			return nil, err
+		}
+
+		assigned := slices.Concat(pr.AssignedReviewers, selection.Reviewers)
+		if len(selection.Reviewers) > 0 {
+			added[pr.PullRequestID] = selection.Reviewers
+		}
+		if need := len(assigned) < team.RequiredReviewers; need != pr.NeedMoreReviewers {
+			needMoreReviewers[pr.PullRequestID] = need
+		}
+
+		result[pr.PullRequestID] = domain.BackfillResult{
+			PullRequestID:     pr.PullRequestID,
+			AddedReviewers:    selection.Reviewers,
+			AssignedReviewers: assigned,
+			NeedMoreReviewers: len(assigned) < team.RequiredReviewers,
+		}
+	}
+
+	if err = u.PullRequestRepository.AddReviewers(ctx, added); err != nil {
+		return nil, err
+	}
+
+	if err = u.PullRequestRepository.SetNeedMoreReviewersByIDs(ctx, needMoreReviewers); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
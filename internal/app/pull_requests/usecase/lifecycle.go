@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"log"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+)
+
+func (u *usecase) ClosePullRequest(ctx context.Context, pullRequestID string, expectedVersion *int64) (domain.PullRequest, error) {
+	var pr domain.PullRequest
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		pr, err = u.closePullRequest(ctx, pullRequestID, expectedVersion)
+		return err
+	})
+	return pr, err
+}
+
+func (u *usecase) MarkReady(ctx context.Context, pullRequestID string, expectedVersion *int64) (domain.PullRequest, error) {
+	var pr domain.PullRequest
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		pr, err = u.openPullRequest(ctx, "pull_request.Usecase.MarkReady", pullRequestID, domain.DRAFT, expectedVersion)
+		return err
+	})
+	return pr, err
+}
+
+func (u *usecase) ReopenPullRequest(ctx context.Context, pullRequestID string, expectedVersion *int64) (domain.PullRequest, error) {
+	var pr domain.PullRequest
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		pr, err = u.openPullRequest(ctx, "pull_request.Usecase.ReopenPullRequest", pullRequestID, domain.CLOSED, expectedVersion)
+		return err
+	})
+	return pr, err
+}
+
+// CloseAuthoredPullRequests closes OPEN and DRAFT pull requests of the authors
+// and returns their ids.
+func (u *usecase) CloseAuthoredPullRequests(ctx context.Context, authorIDs []string) ([]string, error) {
+	const op = "pull_request.Usecase.CloseAuthoredPullRequests"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]string, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	if len(authorIDs) == 0 {
+		return []string{}, nil
+	}
+
+	var prIDs []string
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if prIDs, err = u.PullRequestRepository.FindActiveIDsByAuthors(ctx, authorIDs); err != nil {
+			return err
+		}
+
+		for _, prID := range prIDs {
+			if _, err = u.closePullRequest(ctx, prID, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fail(domain.INTERNAL, "failed to close pull requests", err)
+	}
+
+	return prIDs, nil
+}
+
+// closePullRequest abandons an OPEN or DRAFT pull request. Its reviewers are kept
+// for history and a later reopen; load, capacity and review stats only count
+// reviews by the pull request status, so a CLOSED one no longer counts.
+func (u *usecase) closePullRequest(ctx context.Context, pullRequestID string, expectedVersion *int64) (domain.PullRequest, error) {
+	const op = "pull_request.Usecase.ClosePullRequest"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.PullRequest, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.PullRequest{}, domain.NewError(code, message, err)
+	}
+
+	if err := u.PullRequestRepository.LockPullRequest(ctx, pullRequestID); err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	pr, err := u.PullRequestRepository.FetchByID(ctx, pullRequestID)
+	if err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	if !domain.VersionMatches(expectedVersion, pr.Version) {
+		return fail(domain.PRECONDITION_FAILED, "PR was modified", nil)
+	}
+
+	switch pr.Status {
+	case domain.CLOSED:
+		return pr, nil
+	case domain.MERGED:
+		return fail(domain.PR_MERGED, "PR was merged", nil)
+	}
+
+	if err = u.PullRequestRepository.UpdateStatus(ctx, pullRequestID, domain.CLOSED); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = u.PullRequestRepository.SetNeedMoreReviewers(ctx, pullRequestID, false); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	closed, err := u.PullRequestRepository.FetchByID(ctx, pullRequestID)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return closed, nil
+}
+
+// openPullRequest moves a pull request from the given status to OPEN and assigns
+// missing reviewers by the same rules as CreatePullRequest. Reviewers kept from
+// before the pull request was closed stay, unless they were deactivated since.
+// An already OPEN pull request is returned as is.
+func (u *usecase) openPullRequest(ctx context.Context, op string, pullRequestID string, from domain.Status, expectedVersion *int64) (domain.PullRequest, error) {
+	fail := func(code domain.ErrorCode, message string, err error) (domain.PullRequest, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.PullRequest{}, domain.NewError(code, message, err)
+	}
+
+	if err := u.PullRequestRepository.LockPullRequest(ctx, pullRequestID); err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	pr, err := u.PullRequestRepository.FetchByID(ctx, pullRequestID)
+	if err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	if !domain.VersionMatches(expectedVersion, pr.Version) {
+		return fail(domain.PRECONDITION_FAILED, "PR was modified", nil)
+	}
+
+	if pr.Status == domain.OPEN {
+		return pr, nil
+	}
+	if pr.Status != from {
+		code, message := statusConflict(pr.Status)
+		return fail(code, message, nil)
+	}
+
+	team, err := u.authorTeam(ctx, pr.AuthorID)
+	if err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	if err = u.lockSelectionTeams(ctx, team); err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	for _, reviewerID := range pr.AssignedReviewers {
+		// A deleted reviewer is not found and is dropped like an inactive one.
+		reviewer, err := u.UsersRepository.FetchByID(ctx, reviewerID)
+		if err != nil && !domain.HasCode(err, domain.NOT_FOUND) {
+			return fail(domain.INTERNAL, "internal server error", err)
+		}
+		if err == nil && reviewer.IsActive {
+			continue
+		}
+
+		if err = u.PullRequestRepository.DeleteReviewer(ctx, pullRequestID, reviewerID); err != nil {
+			return fail(domain.INTERNAL, "failed to release reviewers", err)
+		}
+	}
+
+	if err = u.PullRequestRepository.UpdateStatus(ctx, pullRequestID, domain.OPEN); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if _, err = u.backfillPullRequest(ctx, pullRequestID); err != nil {
+		return fail(domain.INTERNAL, "failed to assign reviewers", err)
+	}
+
+	opened, err := u.PullRequestRepository.FetchByID(ctx, pullRequestID)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return opened, nil
+}
+
+// statusConflict describes why reviewers of a pull request in a status other than
+// OPEN cannot be changed.
+func statusConflict(status domain.Status) (domain.ErrorCode, string) {
+	switch status {
+	case domain.DRAFT:
+		return domain.PR_DRAFT, "PR is a draft"
+	case domain.CLOSED:
+		return domain.PR_CLOSED, "PR was closed"
+	default:
+		return domain.PR_MERGED, "PR was merged"
+	}
+}
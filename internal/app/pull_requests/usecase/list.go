@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+)
+
+func (u *usecase) GetPullRequest(ctx context.Context, pullRequestID string) (domain.PullRequest, error) {
+	return u.PullRequestRepository.FetchByID(ctx, pullRequestID)
+}
+
+// ListPullRequests reads one page of pull requests. One extra row is requested
+// to find out whether the next page exists.
+func (u *usecase) ListPullRequests(ctx context.Context, filter domain.PullRequestFilter) (domain.PullRequestPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = domain.DefaultPullRequestPageSize
+	}
+	limit := filter.Limit
+	filter.Limit++
+
+	prs, err := u.PullRequestRepository.ListPullRequests(ctx, filter)
+	if err != nil {
+		return domain.PullRequestPage{}, err
+	}
+
+	page := domain.PullRequestPage{PullRequests: prs}
+	if len(prs) > limit {
+		page.PullRequests = prs[:limit]
+		page.NextCursor = pageCursor(page.PullRequests[limit-1], filter.SortBy, filter.Desc).Encode()
+	}
+
+	return page, nil
+}
+
+func pageCursor(last domain.PullRequest, sortBy domain.PullRequestSort, desc bool) domain.PullRequestCursor {
+	cursor := domain.PullRequestCursor{ID: last.PullRequestID, SortBy: sortBy, Desc: desc}
+	switch sortBy {
+	case domain.SORT_BY_ID:
+	case domain.SORT_BY_MERGED_AT:
+		cursor.Value = last.MergedAt.UTC().Format(time.RFC3339Nano)
+	default:
+		cursor.Value = last.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return cursor
+}
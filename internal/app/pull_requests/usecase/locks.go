@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"slices"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/app/pull_requests"
+)
+
+// Every writer takes its locks in the same order: pull request rows first, by
+// pull_request_id, then team rows, by team_name. Teams are locked all at once
+// with LockTeams; locking a team the transaction already holds does not wait.
+
+// LockForChange takes the locks a change of users or teams needs before it
+// touches their work: the pull requests of the scope, then its teams together
+// with the teams reviewers of those pull requests are picked from.
+func (u *usecase) LockForChange(ctx context.Context, scope pull_requests.LockScope) error {
+	return u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		return u.lockForChange(ctx, scope)
+	})
+}
+
+func (u *usecase) lockForChange(ctx context.Context, scope pull_requests.LockScope) error {
+	const op = "pull_request.Usecase.LockForChange"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	prIDs, err := u.scopePullRequestIDs(ctx, scope)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = u.PullRequestRepository.LockPullRequests(ctx, prIDs); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	prs, err := u.PullRequestRepository.FetchByIDs(ctx, prIDs)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	authorTeams, err := u.selectionTeams(ctx, prs)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = u.TeamsRepository.LockTeams(ctx, slices.Concat(scope.TeamNames, scope.AuthorTeams, authorTeams)); err != nil {
+		return fail(domain.NOT_FOUND, "team not found", err)
+	}
+
+	// Pull requests created before the team locks were taken are locked as well,
+	// nothing can assign the users any more while the teams are held.
+	current, err := u.scopePullRequestIDs(ctx, scope)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	var created []string
+	for _, prID := range current {
+		if !slices.Contains(prIDs, prID) {
+			created = append(created, prID)
+		}
+	}
+
+	if err = u.PullRequestRepository.LockPullRequests(ctx, created); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
+}
+
+// scopePullRequestIDs returns OPEN and DRAFT pull requests the users of the scope
+// author, OPEN ones they review and all pull requests of its author teams, sorted.
+func (u *usecase) scopePullRequestIDs(ctx context.Context, scope pull_requests.LockScope) ([]string, error) {
+	var prIDs []string
+	if len(scope.UserIDs) > 0 {
+		authored, err := u.PullRequestRepository.FindActiveIDsByAuthors(ctx, scope.UserIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		reviewed, err := u.PullRequestRepository.FindOpenReviewIDs(ctx, scope.UserIDs)
+		if err != nil {
+			return nil, err
+		}
+		prIDs = slices.Concat(authored, reviewed)
+	}
+
+	if len(scope.AuthorTeams) > 0 {
+		attributed, err := u.PullRequestRepository.FindIDsByAuthorTeams(ctx, scope.AuthorTeams)
+		if err != nil {
+			return nil, err
+		}
+		prIDs = append(prIDs, attributed...)
+	}
+
+	slices.Sort(prIDs)
+	return slices.Compact(prIDs), nil
+}
+
+// selectionTeams returns the teams reviewers of the OPEN pull requests are picked
+// from: the author's team and its fallback teams. Authors without a team are
+// skipped, there is nothing to pick from for them.
+func (u *usecase) selectionTeams(ctx context.Context, prs []domain.PullRequest) ([]string, error) {
+	var teamNames []string
+	seen := make(map[string]bool)
+	for _, pr := range prs {
+		if pr.Status != domain.OPEN || seen[pr.AuthorID] {
+			continue
+		}
+		seen[pr.AuthorID] = true
+
+		team, err := u.authorTeam(ctx, pr.AuthorID)
+		if err != nil {
+			if domain.HasCode(err, domain.NOT_FOUND) {
+				continue
+			}
+			return nil, err
+		}
+		teamNames = append(teamNames, team.TeamName)
+		teamNames = append(teamNames, team.FallbackTeams...)
+	}
+	return teamNames, nil
+}
+
+// lockSelectionTeams locks the team and its fallback teams, the teams reviewers
+// are picked from. Candidates are read only from the teams of this snapshot of the
+// team, so all of them are locked even if its fallbacks change meanwhile.
+func (u *usecase) lockSelectionTeams(ctx context.Context, team domain.Team) error {
+	return u.TeamsRepository.LockTeams(ctx, append([]string{team.TeamName}, team.FallbackTeams...))
+}
@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"context"
+	"log"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+)
+
+// PreviewAssignment runs the same selection as CreatePullRequest without writing
+// anything and explains why the other candidates were not picked.
+func (u *usecase) PreviewAssignment(ctx context.Context, authorID string) (domain.AssignmentPreview, error) {
+	const op = "pull_request.Usecase.PreviewAssignment"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.AssignmentPreview, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.AssignmentPreview{}, domain.NewError(code, message, err)
+	}
+
+	team, err := u.authorTeam(ctx, authorID)
+	if err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	selection, err := u.pickReviewers(ctx, team, authorExclusion(authorID), team.RequiredReviewers, true)
+	if err != nil {
+		return fail(domain.INTERNAL, "failed to select reviewers", err)
+	}
+
+	return domain.AssignmentPreview{
+		AuthorID:          authorID,
+		TeamName:          team.TeamName,
+		RequiredReviewers: team.RequiredReviewers,
+		NeedMoreReviewers: len(selection.Reviewers) < team.RequiredReviewers,
+		ReviewerSelection: selection,
+	}, nil
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"slices"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+)
+
+// ReleaseReviewers takes all OPEN reviews away from the users and assigns the
+// freed slots to other candidates with the usual selection rules. Users must be
+// unable to review already (e.g. deactivated), otherwise they may be picked again.
+func (u *usecase) ReleaseReviewers(ctx context.Context, userIDs []string) ([]domain.ReassignmentResult, error) {
+	var result []domain.ReassignmentResult
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = u.releaseReviewers(ctx, userIDs)
+		return err
+	})
+	return result, err
+}
+
+func (u *usecase) releaseReviewers(ctx context.Context, userIDs []string) ([]domain.ReassignmentResult, error) {
+	const op = "pull_request.Usecase.ReleaseReviewers"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]domain.ReassignmentResult, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	result := []domain.ReassignmentResult{}
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	// The reviewed pull requests are locked before anything else, the status is
+	// checked again by ReleaseOpenReviews under the lock.
+	reviewed, err := u.PullRequestRepository.FindOpenReviewIDs(ctx, userIDs)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = u.PullRequestRepository.LockPullRequests(ctx, reviewed); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	released, err := u.PullRequestRepository.ReleaseOpenReviews(ctx, userIDs)
+	if err != nil {
+		return fail(domain.INTERNAL, "failed to release reviews", err)
+	}
+
+	prIDs := make([]string, 0, len(released))
+	for prID := range released {
+		prIDs = append(prIDs, prID)
+	}
+	slices.Sort(prIDs)
+
+	filled, err := u.backfillPullRequests(ctx, prIDs)
+	if err != nil {
+		return fail(domain.INTERNAL, "failed to reassign reviewers", err)
+	}
+
+	for _, prID := range prIDs {
+		result = append(result, domain.ReassignmentResult{
+			RemovedReviewers: released[prID],
+			BackfillResult:   filled[prID],
+		})
+	}
+
+	return result, nil
+}
+
+// ReleaseForeignReviews reassigns OPEN reviews the user can no longer do after
+// moving to another team, i.e. those of PRs whose author's team neither is the
+// user's team nor has it as a fallback.
+func (u *usecase) ReleaseForeignReviews(ctx context.Context, userID string) ([]domain.ReassignmentResult, error) {
+	var result []domain.ReassignmentResult
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = u.releaseForeignReviews(ctx, userID)
+		return err
+	})
+	return result, err
+}
+
+func (u *usecase) releaseForeignReviews(ctx context.Context, userID string) ([]domain.ReassignmentResult, error) {
+	const op = "pull_request.Usecase.ReleaseForeignReviews"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]domain.ReassignmentResult, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	prIDs, err := u.PullRequestRepository.FindForeignReviewIDs(ctx, userID)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	result := []domain.ReassignmentResult{}
+	if len(prIDs) == 0 {
+		return result, nil
+	}
+
+	if err = u.PullRequestRepository.LockPullRequests(ctx, prIDs); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	for _, prID := range prIDs {
+		if err = u.PullRequestRepository.DeleteReviewer(ctx, prID, userID); err != nil {
+			return fail(domain.INTERNAL, "failed to release reviews", err)
+		}
+	}
+
+	filled, err := u.backfillPullRequests(ctx, prIDs)
+	if err != nil {
+		return fail(domain.INTERNAL, "failed to reassign reviewers", err)
+	}
+
+	for _, prID := range prIDs {
+		result = append(result, domain.ReassignmentResult{
+			RemovedReviewers: []string{userID},
+			BackfillResult:   filled[prID],
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/app/users"
+)
+
+// candidatePool caches the candidates of each team for a batch of assignments and
+// counts the reviews assigned within the batch, so that capacity holds across it.
+type candidatePool struct {
+	users users.Repository
+	teams map[string][]domain.Candidate
+}
+
+func (u *usecase) newCandidatePool() *candidatePool {
+	return &candidatePool{users: u.UsersRepository, teams: make(map[string][]domain.Candidate)}
+}
+
+func (p *candidatePool) members(ctx context.Context, teamName string) ([]domain.Candidate, error) {
+	if members, ok := p.teams[teamName]; ok {
+		return members, nil
+	}
+
+	members, err := p.users.GetCandidatesByTeam(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	p.teams[teamName] = members
+	return members, nil
+}
+
+func (p *candidatePool) assign(userIDs []string) {
+	for _, members := range p.teams {
+		for i := range members {
+			if slices.Contains(userIDs, members[i].UserID) {
+				members[i].OpenReviews++
+			}
+		}
+	}
+}
+
+// pickReviewers selects up to count reviewers from the home team and, while there
+// are not enough of them, from its fallback teams in order. Users from exclude are
+// rejected with the given reason, as well as inactive, absent and fully loaded ones.
+// With dryRun set the selector state is left untouched.
+func (u *usecase) pickReviewers(ctx context.Context, homeTeam domain.Team, exclude map[string]domain.RejectionReason, count int, dryRun bool) (domain.ReviewerSelection, error) {
+	return u.pickReviewersFrom(ctx, u.newCandidatePool(), homeTeam, exclude, count, dryRun)
+}
+
+// pickReviewersFrom does the same as pickReviewers with candidates taken from
+// the pool, which then counts the picked reviewers in.
+func (u *usecase) pickReviewersFrom(ctx context.Context, pool *candidatePool, homeTeam domain.Team, exclude map[string]domain.RejectionReason, count int, dryRun bool) (domain.ReviewerSelection, error) {
+	selection := domain.ReviewerSelection{
+		Reviewers:         []string{},
+		FallbackReviewers: []string{},
+		Rejected:          []domain.RejectedCandidate{},
+	}
+
+	pick := u.ReviewerSelector.Select
+	if dryRun {
+		pick = u.ReviewerSelector.Peek
+	}
+
+	teamNames := append([]string{homeTeam.TeamName}, homeTeam.FallbackTeams...)
+	for _, teamName := range teamNames {
+		if len(selection.Reviewers) >= count {
+			break
+		}
+
+		members, err := pool.members(ctx, teamName)
+		if err != nil {
+			return domain.ReviewerSelection{}, err
+		}
+
+		var candidates []domain.Candidate
+		for _, member := range members {
+			if reason, rejected := rejectionReason(member, exclude); rejected {
+				selection.Rejected = append(selection.Rejected, rejectedCandidate(member, reason))
+				continue
+			}
+			candidates = append(candidates, member)
+		}
+
+		selected, err := pick(ctx, teamName, candidates, count-len(selection.Reviewers))
+		if err != nil {
+			return domain.ReviewerSelection{}, err
+		}
+
+		for _, userID := range selected {
+			selection.Reviewers = append(selection.Reviewers, userID)
+			if teamName != homeTeam.TeamName {
+				selection.FallbackReviewers = append(selection.FallbackReviewers, userID)
+			}
+		}
+
+		for _, candidate := range candidates {
+			if !slices.Contains(selected, candidate.UserID) {
+				selection.Rejected = append(selection.Rejected, rejectedCandidate(candidate, domain.REJECTED_NOT_SELECTED))
+			}
+		}
+	}
+
+	if !dryRun {
+		pool.assign(selection.Reviewers)
+	}
+
+	return selection, nil
+}
+
+func rejectionReason(candidate domain.Candidate, exclude map[string]domain.RejectionReason) (domain.RejectionReason, bool) {
+	if reason, ok := exclude[candidate.UserID]; ok {
+		return reason, true
+	}
+
+	switch {
+	case !candidate.IsActive:
+		return domain.REJECTED_INACTIVE, true
+	case candidate.Absent:
+		return domain.REJECTED_UNAVAILABLE, true
+	case candidate.AtCapacity():
+		return domain.REJECTED_AT_CAPACITY, true
+	default:
+		return "", false
+	}
+}
+
+func rejectedCandidate(candidate domain.Candidate, reason domain.RejectionReason) domain.RejectedCandidate {
+	return domain.RejectedCandidate{
+		UserID:      candidate.UserID,
+		TeamName:    candidate.TeamName,
+		Reason:      reason,
+		OpenReviews: candidate.OpenReviews,
+	}
+}
+
+func (u *usecase) authorTeam(ctx context.Context, authorID string) (domain.Team, error) {
+	author, err := u.UsersRepository.FetchByID(ctx, authorID)
+	if err != nil {
+		return domain.Team{}, err
+	}
+
+	return u.TeamsRepository.FetchTeamByName(ctx, author.TeamName)
+}
+
+// lockReviewerTeam takes the lock every assignment holds on the teams it picks
+// reviewers from, home and fallback ones, so the open reviews counted for the
+// capacity check cannot change until the transaction ends. Pull request rows must
+// be locked before it.
+func (u *usecase) lockReviewerTeam(ctx context.Context, userID string) error {
+	user, err := u.UsersRepository.FetchByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return u.TeamsRepository.LockTeam(ctx, user.TeamName)
+}
+
+// checkReviewer reports why userID cannot be assigned with the given exclusions.
+func (u *usecase) checkReviewer(ctx context.Context, userID string, exclude map[string]domain.RejectionReason) (domain.RejectionReason, bool, error) {
+	user, err := u.UsersRepository.FetchByID(ctx, userID)
+	if err != nil {
+		return "", false, err
+	}
+
+	members, err := u.UsersRepository.GetCandidatesByTeam(ctx, user.TeamName)
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, member := range members {
+		if member.UserID == userID {
+			reason, rejected := rejectionReason(member, exclude)
+			return reason, rejected, nil
+		}
+	}
+
+	return "", false, fmt.Errorf("user %s is not a member of team %s", userID, user.TeamName)
+}
+
+// refreshPullRequest refetches the pull request and brings need_more_reviewers in
+// line with its current reviewers.
+func (u *usecase) refreshPullRequest(ctx context.Context, prID string) (domain.PullRequest, error) {
+	pr, err := u.PullRequestRepository.FetchByID(ctx, prID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	team, err := u.authorTeam(ctx, pr.AuthorID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	needMoreReviewers := len(pr.AssignedReviewers) < team.RequiredReviewers
+	if needMoreReviewers != pr.NeedMoreReviewers {
+		if err = u.PullRequestRepository.SetNeedMoreReviewers(ctx, prID, needMoreReviewers); err != nil {
+			return domain.PullRequest{}, err
+		}
+		pr.NeedMoreReviewers = needMoreReviewers
+	}
+
+	return pr, nil
+}
+
+func authorExclusion(authorID string) map[string]domain.RejectionReason {
+	return map[string]domain.RejectionReason{authorID: domain.REJECTED_AUTHOR}
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"slices"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+)
+
+func (u *usecase) UpdatePullRequest(ctx context.Context, pullRequestID string, update domain.PullRequestUpdate, expectedVersion *int64) (domain.PullRequest, error) {
+	var pr domain.PullRequest
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		pr, err = u.updatePullRequest(ctx, pullRequestID, update, expectedVersion)
+		return err
+	})
+	return pr, err
+}
+
+// updatePullRequest changes the name, metadata and author of the pull request.
+// The author can only be changed while the PR is OPEN or DRAFT; if the new author
+// reviews the PR, they are removed from reviewers and a replacement is picked.
+func (u *usecase) updatePullRequest(ctx context.Context, pullRequestID string, update domain.PullRequestUpdate, expectedVersion *int64) (domain.PullRequest, error) {
+	const op = "pull_request.Usecase.UpdatePullRequest"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.PullRequest, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.PullRequest{}, domain.NewError(code, message, err)
+	}
+
+	if err := u.PullRequestRepository.LockPullRequest(ctx, pullRequestID); err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	pr, err := u.PullRequestRepository.FetchByID(ctx, pullRequestID)
+	if err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	if !domain.VersionMatches(expectedVersion, pr.Version) {
+		return fail(domain.PRECONDITION_FAILED, "PR was modified", nil)
+	}
+
+	authorChanged := update.AuthorID != nil && *update.AuthorID != pr.AuthorID
+	if authorChanged {
+		if pr.Status != domain.OPEN && pr.Status != domain.DRAFT {
+			code, message := statusConflict(pr.Status)
+			return fail(code, message, nil)
+		}
+
+		author, err := u.UsersRepository.FetchByID(ctx, *update.AuthorID)
+		if err != nil {
+			return fail(domain.NOT_FOUND, "author not found", err)
+		}
+
+		team, err := u.TeamsRepository.FetchTeamByName(ctx, author.TeamName)
+		if err != nil {
+			return fail(domain.NOT_FOUND, "author team not found", err)
+		}
+
+		if err = u.lockSelectionTeams(ctx, team); err != nil {
+			return fail(domain.NOT_FOUND, "author team not found", err)
+		}
+
+		pr.AuthorID = author.UserID
+	}
+
+	setIfPresent(&pr.PullRequestName, update.PullRequestName)
+	setIfPresent(&pr.URL, update.URL)
+	setIfPresent(&pr.Repository, update.Repository)
+	setIfPresent(&pr.SourceBranch, update.SourceBranch)
+	setIfPresent(&pr.TargetBranch, update.TargetBranch)
+	setIfPresent(&pr.Description, update.Description)
+
+	if err = u.PullRequestRepository.UpdatePullRequest(ctx, &pr); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if authorChanged && pr.Status == domain.OPEN {
+		if slices.Contains(pr.AssignedReviewers, pr.AuthorID) {
+			if err = u.PullRequestRepository.DeleteReviewer(ctx, pullRequestID, pr.AuthorID); err != nil {
+				return fail(domain.INTERNAL, "failed to remove the author from reviewers", err)
+			}
+		}
+
+		if _, err = u.backfillPullRequest(ctx, pullRequestID); err != nil {
+			return fail(domain.INTERNAL, "failed to assign reviewers", err)
+		}
+	}
+
+	updated, err := u.PullRequestRepository.FetchByID(ctx, pullRequestID)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return updated, nil
+}
+
+func setIfPresent(target *string, value *string) {
+	if value != nil {
+		*target = *value
+	}
+}
@@ -2,23 +2,73 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"slices"
 
 	"github.com/leoscrowi/pr-assignment-service/domain"
 	"github.com/leoscrowi/pr-assignment-service/internal/app/pull_requests"
+	"github.com/leoscrowi/pr-assignment-service/internal/app/teams"
 	"github.com/leoscrowi/pr-assignment-service/internal/app/users"
+	"github.com/leoscrowi/pr-assignment-service/internal/transaction"
 )
 
 type usecase struct {
 	PullRequestRepository pull_requests.Repository
 	UsersRepository       users.Repository
+	TeamsRepository       teams.Repository
+	ReviewerSelector      pull_requests.ReviewerSelector
+	TxManager             transaction.Manager
 }
 
-func NewUsecase(prRepository pull_requests.Repository, usRepository users.Repository) *usecase {
-	return &usecase{PullRequestRepository: prRepository, UsersRepository: usRepository}
+func NewUsecase(prRepository pull_requests.Repository, usRepository users.Repository, tRepository teams.Repository, selector pull_requests.ReviewerSelector, txManager transaction.Manager) *usecase {
+	return &usecase{PullRequestRepository: prRepository, UsersRepository: usRepository, TeamsRepository: tRepository, ReviewerSelector: selector, TxManager: txManager}
 }
 
-func (u *usecase) ReassignPullRequest(ctx context.Context, pullRequestID string, oldUserID string) (domain.PullRequest, string, error) {
+func (u *usecase) ReassignPullRequest(ctx context.Context, pullRequestID string, oldUserID string, newUserID string, expectedVersion *int64) (domain.PullRequest, string, error) {
+	var (
+		pr         domain.PullRequest
+		replacedBy string
+	)
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		pr, replacedBy, err = u.reassignPullRequest(ctx, pullRequestID, oldUserID, newUserID, expectedVersion)
+		return err
+	})
+	return pr, replacedBy, err
+}
+
+func (u *usecase) AddReviewer(ctx context.Context, pullRequestID string, userID string, expectedVersion *int64) (domain.PullRequest, error) {
+	var pr domain.PullRequest
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		pr, err = u.addReviewer(ctx, pullRequestID, userID, expectedVersion)
+		return err
+	})
+	return pr, err
+}
+
+func (u *usecase) RemoveReviewer(ctx context.Context, pullRequestID string, userID string, expectedVersion *int64) (domain.PullRequest, error) {
+	var pr domain.PullRequest
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		pr, err = u.removeReviewer(ctx, pullRequestID, userID, expectedVersion)
+		return err
+	})
+	return pr, err
+}
+
+func (u *usecase) CreatePullRequest(ctx context.Context, pullRequest *domain.PullRequest) (domain.PullRequest, error) {
+	var pr domain.PullRequest
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		pr, err = u.createPullRequest(ctx, pullRequest)
+		return err
+	})
+	return pr, err
+}
+
+func (u *usecase) reassignPullRequest(ctx context.Context, pullRequestID string, oldUserID string, newUserID string, expectedVersion *int64) (domain.PullRequest, string, error) {
 	const op = "pull_request.Usecase.ReassignPullRequest"
 
 	fail := func(code domain.ErrorCode, message string, err error) (domain.PullRequest, string, error) {
@@ -26,13 +76,22 @@ func (u *usecase) ReassignPullRequest(ctx context.Context, pullRequestID string,
 		return domain.PullRequest{}, "", domain.NewError(code, message, err)
 	}
 
+	if err := u.PullRequestRepository.LockPullRequest(ctx, pullRequestID); err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
 	pr, err := u.PullRequestRepository.FetchByID(ctx, pullRequestID)
 	if err != nil {
 		return fail(domain.NOT_FOUND, "resource not found", err)
 	}
 
-	if pr.Status == domain.MERGED {
-		return fail(domain.PR_MERGED, "PR was merged", err)
+	if !domain.VersionMatches(expectedVersion, pr.Version) {
+		return fail(domain.PRECONDITION_FAILED, "PR was modified", nil)
+	}
+
+	if pr.Status != domain.OPEN {
+		code, message := statusConflict(pr.Status)
+		return fail(code, message, nil)
 	}
 
 	revs, err := u.PullRequestRepository.GetReviewersID(ctx, pullRequestID)
@@ -52,31 +111,47 @@ func (u *usecase) ReassignPullRequest(ctx context.Context, pullRequestID string,
 		return fail(domain.NOT_FOUND, "reviewer is not found", nil)
 	}
 
-	oldUser, err := u.UsersRepository.FetchByID(ctx, oldUserID)
-	if err != nil {
-		return fail(domain.NOT_FOUND, "user to replace not found", err)
+	exclude := authorExclusion(pr.AuthorID)
+	for _, revID := range revs {
+		exclude[revID] = domain.REJECTED_ALREADY_ASSIGNED
 	}
 
-	activeUsers, err := u.UsersRepository.GetActiveUsersIDByTeam(ctx, oldUser.TeamName)
-	if err != nil {
-		return fail(domain.INTERNAL, "failed to get active team members", err)
-	}
+	if newUserID != "" {
+		if err = u.lockReviewerTeam(ctx, newUserID); err != nil {
+			return fail(domain.NOT_FOUND, "user not found", err)
+		}
 
-	currentReviewersSet := make(map[string]bool)
-	for _, revID := range revs {
-		currentReviewersSet[revID] = true
-	}
+		reason, rejected, err := u.checkReviewer(ctx, newUserID, exclude)
+		if err != nil {
+			return fail(domain.NOT_FOUND, "user not found", err)
+		}
+		if rejected {
+			return fail(domain.INVALID_REVIEWER, fmt.Sprintf("user cannot review this PR: %s", reason), nil)
+		}
+	} else {
+		oldUser, err := u.UsersRepository.FetchByID(ctx, oldUserID)
+		if err != nil {
+			return fail(domain.NOT_FOUND, "user to replace not found", err)
+		}
 
-	var newUserID string
-	for _, userID := range activeUsers {
-		if userID != oldUserID && !currentReviewersSet[userID] && userID != pr.AuthorID {
-			newUserID = userID
-			break
+		homeTeam, err := u.TeamsRepository.FetchTeamByName(ctx, oldUser.TeamName)
+		if err != nil {
+			return fail(domain.NOT_FOUND, "reviewer team not found", err)
+		}
+
+		if err = u.lockSelectionTeams(ctx, homeTeam); err != nil {
+			return fail(domain.NOT_FOUND, "reviewer team not found", err)
+		}
+
+		selection, err := u.pickReviewers(ctx, homeTeam, exclude, 1, false)
+		if err != nil {
+			return fail(domain.INTERNAL, "failed to select replacement", err)
 		}
-	}
 
-	if newUserID == "" {
-		return fail(domain.NO_CANDIDATE, "no active replacement candidate in team", nil)
+		if len(selection.Reviewers) == 0 {
+			return fail(domain.NO_CANDIDATE, "no active replacement candidate in team", nil)
+		}
+		newUserID = selection.Reviewers[0]
 	}
 
 	err = u.PullRequestRepository.DeleteReviewer(ctx, pullRequestID, oldUserID)
@@ -86,22 +161,136 @@ func (u *usecase) ReassignPullRequest(ctx context.Context, pullRequestID string,
 
 	err = u.PullRequestRepository.AddReviewer(ctx, pullRequestID, newUserID)
 	if err != nil {
-		err := u.PullRequestRepository.AddReviewer(ctx, pullRequestID, oldUserID)
-		if err != nil {
-			return fail(domain.NOT_ASSIGNED, "failer to add new reviewer", err)
-		}
 		return fail(domain.NOT_ASSIGNED, "failed to add new reviewer", err)
 	}
 
-	updatedPR, err := u.PullRequestRepository.FetchByID(ctx, pullRequestID)
+	updatedPR, err := u.refreshPullRequest(ctx, pullRequestID)
 	if err != nil {
-		return fail(domain.NOT_FOUND, "resource is not found", err)
+		return fail(domain.INTERNAL, "failed to update reviewers flag", err)
 	}
 
 	return updatedPR, newUserID, nil
 }
 
-func (u *usecase) MergePullRequest(ctx context.Context, pullRequestID string) (domain.PullRequest, error) {
+func (u *usecase) addReviewer(ctx context.Context, pullRequestID string, userID string, expectedVersion *int64) (domain.PullRequest, error) {
+	const op = "pull_request.Usecase.AddReviewer"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.PullRequest, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.PullRequest{}, domain.NewError(code, message, err)
+	}
+
+	if err := u.PullRequestRepository.LockPullRequest(ctx, pullRequestID); err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	pr, err := u.PullRequestRepository.FetchByID(ctx, pullRequestID)
+	if err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	if !domain.VersionMatches(expectedVersion, pr.Version) {
+		return fail(domain.PRECONDITION_FAILED, "PR was modified", nil)
+	}
+
+	if pr.Status != domain.OPEN {
+		code, message := statusConflict(pr.Status)
+		return fail(code, message, nil)
+	}
+
+	team, err := u.authorTeam(ctx, pr.AuthorID)
+	if err != nil {
+		return fail(domain.NOT_FOUND, "author team not found", err)
+	}
+
+	exclude := authorExclusion(pr.AuthorID)
+	for _, revID := range pr.AssignedReviewers {
+		exclude[revID] = domain.REJECTED_ALREADY_ASSIGNED
+	}
+
+	if err = u.lockReviewerTeam(ctx, userID); err != nil {
+		return fail(domain.NOT_FOUND, "user not found", err)
+	}
+
+	reason, rejected, err := u.checkReviewer(ctx, userID, exclude)
+	if err != nil {
+		return fail(domain.NOT_FOUND, "user not found", err)
+	}
+	if rejected {
+		return fail(domain.INVALID_REVIEWER, fmt.Sprintf("user cannot review this PR: %s", reason), nil)
+	}
+
+	if len(pr.AssignedReviewers) >= team.RequiredReviewers {
+		return fail(domain.REVIEWERS_LIMIT, "PR already has enough reviewers", nil)
+	}
+
+	err = u.PullRequestRepository.AddReviewer(ctx, pullRequestID, userID)
+	if err != nil {
+		return fail(domain.NOT_ASSIGNED, "failed to add reviewer", err)
+	}
+
+	updatedPR, err := u.refreshPullRequest(ctx, pullRequestID)
+	if err != nil {
+		return fail(domain.INTERNAL, "failed to update reviewers flag", err)
+	}
+
+	return updatedPR, nil
+}
+
+func (u *usecase) removeReviewer(ctx context.Context, pullRequestID string, userID string, expectedVersion *int64) (domain.PullRequest, error) {
+	const op = "pull_request.Usecase.RemoveReviewer"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.PullRequest, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.PullRequest{}, domain.NewError(code, message, err)
+	}
+
+	if err := u.PullRequestRepository.LockPullRequest(ctx, pullRequestID); err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	pr, err := u.PullRequestRepository.FetchByID(ctx, pullRequestID)
+	if err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	if !domain.VersionMatches(expectedVersion, pr.Version) {
+		return fail(domain.PRECONDITION_FAILED, "PR was modified", nil)
+	}
+
+	if pr.Status != domain.OPEN {
+		code, message := statusConflict(pr.Status)
+		return fail(code, message, nil)
+	}
+
+	if !slices.Contains(pr.AssignedReviewers, userID) {
+		return fail(domain.NOT_ASSIGNED, "reviewer is not assigned to this PR", nil)
+	}
+
+	err = u.PullRequestRepository.DeleteReviewer(ctx, pullRequestID, userID)
+	if err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	updatedPR, err := u.refreshPullRequest(ctx, pullRequestID)
+	if err != nil {
+		return fail(domain.INTERNAL, "failed to update reviewers flag", err)
+	}
+
+	return updatedPR, nil
+}
+
+func (u *usecase) MergePullRequest(ctx context.Context, pullRequestID string, expectedVersion *int64) (domain.PullRequest, error) {
+	var pr domain.PullRequest
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		pr, err = u.mergePullRequest(ctx, pullRequestID, expectedVersion)
+		return err
+	})
+	return pr, err
+}
+
+func (u *usecase) mergePullRequest(ctx context.Context, pullRequestID string, expectedVersion *int64) (domain.PullRequest, error) {
 	const op = "pull_request.Usecase.MergePullRequest"
 
 	fail := func(code domain.ErrorCode, message string, err error) (domain.PullRequest, error) {
@@ -109,14 +298,26 @@ func (u *usecase) MergePullRequest(ctx context.Context, pullRequestID string) (d
 		return domain.PullRequest{}, domain.NewError(code, message, err)
 	}
 
+	if err := u.PullRequestRepository.LockPullRequest(ctx, pullRequestID); err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
 	pr, err := u.PullRequestRepository.FetchByIDWithMergeAt(ctx, pullRequestID)
 	if err != nil {
 		return fail(domain.NOT_FOUND, "resource not found", err)
 	}
 
+	if !domain.VersionMatches(expectedVersion, pr.Version) {
+		return fail(domain.PRECONDITION_FAILED, "PR was modified", nil)
+	}
+
 	if pr.Status == domain.MERGED {
 		return pr, nil
 	}
+	if pr.Status != domain.OPEN {
+		code, message := statusConflict(pr.Status)
+		return fail(code, message, nil)
+	}
 
 	newPr, err := u.PullRequestRepository.MergePullRequest(ctx, pullRequestID)
 	if err != nil {
@@ -126,7 +327,7 @@ func (u *usecase) MergePullRequest(ctx context.Context, pullRequestID string) (d
 	return newPr, nil
 }
 
-func (u *usecase) CreatePullRequest(ctx context.Context, pullRequest *domain.PullRequest) (domain.PullRequest, error) {
+func (u *usecase) createPullRequest(ctx context.Context, pullRequest *domain.PullRequest) (domain.PullRequest, error) {
 	const op = "pull_request.Usecase.CreatePullRequest"
 
 	fail := func(code domain.ErrorCode, message string, err error) (domain.PullRequest, error) {
@@ -139,24 +340,31 @@ func (u *usecase) CreatePullRequest(ctx context.Context, pullRequest *domain.Pul
 		return fail(domain.NOT_FOUND, "resource not found", err)
 	}
 
-	teamMembersID, err := u.UsersRepository.GetActiveUsersIDByTeam(ctx, user.TeamName)
+	team, err := u.TeamsRepository.FetchTeamByName(ctx, user.TeamName)
 	if err != nil {
 		return fail(domain.NOT_FOUND, "resource not found", err)
 	}
 
+	// Fallback teams are read and assigned from as well, so all of them are locked.
+	if err = u.lockSelectionTeams(ctx, team); err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	// Drafts get their reviewers only when they are marked ready.
 	var reviewers []string
-	for _, teamMemberID := range teamMembersID {
-		if teamMemberID != pullRequest.AuthorID {
-			reviewers = append(reviewers, teamMemberID)
+	if pullRequest.Status == domain.DRAFT {
+		pullRequest.NeedMoreReviewers = false
+	} else {
+		selection, err := u.pickReviewers(ctx, team, authorExclusion(pullRequest.AuthorID), team.RequiredReviewers, false)
+		if err != nil {
+			return fail(domain.INTERNAL, "failed to select reviewers", err)
 		}
+		reviewers = selection.Reviewers
 
-		if len(reviewers) >= 2 {
-			break
-		}
+		pullRequest.Status = domain.OPEN
+		pullRequest.NeedMoreReviewers = len(reviewers) < team.RequiredReviewers
 	}
 
-	pullRequest.Status = domain.OPEN
-
 	err = u.PullRequestRepository.CreatePullRequest(ctx, pullRequest)
 	if err != nil {
 		return fail(domain.PR_EXISTS, "PR is already exists", err)
@@ -169,7 +377,10 @@ func (u *usecase) CreatePullRequest(ctx context.Context, pullRequest *domain.Pul
 		}
 	}
 
-	pullRequest.AssignedReviewers = reviewers
+	created, err := u.PullRequestRepository.FetchByID(ctx, pullRequest.PullRequestID)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
 
-	return *pullRequest, nil
+	return created, nil
 }
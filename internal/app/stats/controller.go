@@ -9,6 +9,9 @@ import (
 
 type Controller interface {
 	GetPullRequestStats(w http.ResponseWriter, r *http.Request)
+	GetTeamStats(w http.ResponseWriter, r *http.Request)
+	GetTimeToMerge(w http.ResponseWriter, r *http.Request)
+	GetTimeToMergeDaily(w http.ResponseWriter, r *http.Request)
 
 	SetupRoutes(r chi.Router, cfg *config.Config)
 }
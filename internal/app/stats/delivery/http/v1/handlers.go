@@ -1,7 +1,10 @@
 package v1
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/leoscrowi/pr-assignment-service/domain"
 	"github.com/leoscrowi/pr-assignment-service/internal/app/stats"
@@ -18,7 +21,13 @@ func NewStatsController(usecase stats.Usecase) *StatsController {
 }
 
 func (c *StatsController) GetPullRequestStats(w http.ResponseWriter, r *http.Request) {
-	st, err := c.usecase.GetPullRequestStats(r.Context())
+	filter, err := parseStatsFilter(r.URL.Query())
+	if err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	st, err := c.usecase.GetPullRequestStats(r.Context(), filter)
 	if err != nil {
 		domain.WriteError(w, domain.ConvertToErrorResponse(err))
 		return
@@ -29,3 +38,125 @@ func (c *StatsController) GetPullRequestStats(w http.ResponseWriter, r *http.Req
 	}
 	utils.WriteHeader(w, http.StatusOK, &resp)
 }
+
+func (c *StatsController) GetTeamStats(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseStatsFilter(r.URL.Query())
+	if err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	st, err := c.usecase.GetTeamStats(r.Context(), filter)
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.GetTeamStatsResponse{
+		TeamStats: st,
+	}
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func (c *StatsController) GetTimeToMerge(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter, err := parseTimeToMergeFilter(query)
+	if err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	groupBy := domain.GROUP_BY_TEAM
+	if raw := query.Get("group_by"); raw != "" {
+		switch group := domain.TimeToMergeGroup(raw); group {
+		case domain.GROUP_BY_TEAM, domain.GROUP_BY_AUTHOR, domain.GROUP_BY_REVIEWER:
+			groupBy = group
+		default:
+			domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("unknown group_by %q", raw)))
+			return
+		}
+	}
+
+	st, err := c.usecase.GetTimeToMerge(r.Context(), filter, groupBy)
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.GetTimeToMergeResponse{
+		GroupBy:     groupBy,
+		TimeToMerge: st,
+	}
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func (c *StatsController) GetTimeToMergeDaily(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseTimeToMergeFilter(r.URL.Query())
+	if err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	st, err := c.usecase.GetTimeToMergeDaily(r.Context(), filter)
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.GetTimeToMergeDailyResponse{
+		TimeToMerge: st,
+	}
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+// parseTimeToMergeFilter reads the usual stats filter, where from/to bound merged_at.
+// Only merged pull requests have a time to merge, so no other status is accepted.
+func parseTimeToMergeFilter(query url.Values) (domain.StatsFilter, error) {
+	filter, err := parseStatsFilter(query)
+	if err != nil {
+		return filter, err
+	}
+
+	if filter.Status != "" && filter.Status != domain.MERGED {
+		return filter, fmt.Errorf("only %s pull requests have a time to merge", domain.MERGED)
+	}
+
+	return filter, nil
+}
+
+func parseStatsFilter(query url.Values) (domain.StatsFilter, error) {
+	filter := domain.StatsFilter{
+		TeamName: query.Get("team"),
+	}
+
+	if raw := query.Get("status"); raw != "" {
+		switch status := domain.Status(raw); status {
+		case domain.DRAFT, domain.OPEN, domain.MERGED, domain.CLOSED:
+			filter.Status = status
+		default:
+			return filter, fmt.Errorf("unknown status %q", raw)
+		}
+	}
+
+	timeParams := map[string]**time.Time{
+		"from": &filter.From,
+		"to":   &filter.To,
+	}
+	for name, target := range timeParams {
+		raw := query.Get(name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, fmt.Errorf("%s: %w", name, err)
+		}
+		*target = &parsed
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return filter, fmt.Errorf("from must be before to")
+	}
+
+	return filter, nil
+}
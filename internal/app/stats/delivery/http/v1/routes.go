@@ -10,6 +10,9 @@ func (c *StatsController) SetupRoutes(r chi.Router, cfg *config.Config) {
 	r.Route("/stats", func(r chi.Router) {
 		r.Use(middleware.AuthMiddleware(cfg))
 		r.Get("/users", c.GetPullRequestStats)
+		r.Get("/teams", c.GetTeamStats)
+		r.Get("/timeToMerge", c.GetTimeToMerge)
+		r.Get("/timeToMerge/daily", c.GetTimeToMergeDaily)
 	})
 
 }
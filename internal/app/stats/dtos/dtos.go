@@ -5,3 +5,16 @@ import "github.com/leoscrowi/pr-assignment-service/domain"
 type GetPullRequestStatsResponse struct {
 	PullRequestStats []domain.PullRequestStats `json:"stats"`
 }
+
+type GetTeamStatsResponse struct {
+	TeamStats []domain.TeamStats `json:"stats"`
+}
+
+type GetTimeToMergeResponse struct {
+	GroupBy     domain.TimeToMergeGroup   `json:"group_by"`
+	TimeToMerge []domain.TimeToMergeStats `json:"stats"`
+}
+
+type GetTimeToMergeDailyResponse struct {
+	TimeToMerge []domain.TimeToMergeDay `json:"stats"`
+}
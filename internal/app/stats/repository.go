@@ -7,5 +7,8 @@ import (
 )
 
 type Repository interface {
-	GetPullRequestStats(ctx context.Context) ([]domain.PullRequestStats, error)
+	GetPullRequestStats(ctx context.Context, filter domain.StatsFilter) ([]domain.PullRequestStats, error)
+	GetTeamStats(ctx context.Context, filter domain.StatsFilter) ([]domain.TeamStats, error)
+	GetTimeToMerge(ctx context.Context, filter domain.StatsFilter, groupBy domain.TimeToMergeGroup) ([]domain.TimeToMergeStats, error)
+	GetTimeToMergeDaily(ctx context.Context, filter domain.StatsFilter) ([]domain.TimeToMergeDay, error)
 }
@@ -7,6 +7,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
 	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/transaction"
 )
 
 type Repository struct {
@@ -17,7 +18,38 @@ func NewStatsRepository(db *sqlx.DB) *Repository {
 	return &Repository{db: db}
 }
 
-func (r *Repository) GetPullRequestStats(ctx context.Context) ([]domain.PullRequestStats, error) {
+// pullRequestConditions restricts pull requests aliased as pr to the filter window
+// and status.
+func pullRequestConditions(filter domain.StatsFilter) sq.And {
+	conditions := sq.And{}
+	if filter.From != nil {
+		conditions = append(conditions, sq.GtOrEq{"pr.created_at": *filter.From})
+	}
+	if filter.To != nil {
+		conditions = append(conditions, sq.Lt{"pr.created_at": *filter.To})
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, sq.Eq{"pr.status": filter.Status})
+	}
+	return conditions
+}
+
+// reviewConditions restricts pull requests aliased as pr like pullRequestConditions.
+// Reviewers stay assigned to CLOSED pull requests, but those reviews are not
+// counted unless CLOSED ones are asked for explicitly.
+func reviewConditions(filter domain.StatsFilter) sq.And {
+	conditions := pullRequestConditions(filter)
+	if filter.Status == "" {
+		conditions = append(conditions, sq.NotEq{"pr.status": domain.CLOSED})
+	}
+	return conditions
+}
+
+// GetPullRequestStats counts reviews of every user among the pull requests matching
+// the filter. Capacity and current load always reflect all OPEN reviews. The team
+// filter keeps the reviews done for the team and lists its current members and
+// everyone who reviewed for it.
+func (r *Repository) GetPullRequestStats(ctx context.Context, filter domain.StatsFilter) ([]domain.PullRequestStats, error) {
 	const op = "stats.Repository.GetPullRequestStats"
 
 	fail := func(code domain.ErrorCode, message string, err error) ([]domain.PullRequestStats, error) {
@@ -25,29 +57,47 @@ func (r *Repository) GetPullRequestStats(ctx context.Context) ([]domain.PullRequ
 		return nil, domain.NewError(code, message, err)
 	}
 
-	tx, err := r.db.BeginTxx(ctx, nil)
+	tx, err := transaction.Begin(ctx, r.db)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	defer func(tx *sqlx.Tx) {
+	defer func(tx *transaction.Tx) {
 		_ = tx.Rollback()
 	}(tx)
 
-	query, args, err := sq.Select(
+	prConditions, prArgs, err := reviewConditions(filter).ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	reviewJoin := "pull_request_reviewers prr JOIN pull_requests pr ON prr.pull_request_id = pr.pull_request_id AND " + prConditions
+	if filter.TeamName != "" {
+		reviewJoin += " AND prr.reviewer_team_name = ?"
+		prArgs = append(prArgs, filter.TeamName)
+	}
+
+	builder := sq.Select(
 		"u.user_id",
 		"u.username",
-		"u.team_name",
+		"COALESCE(u.team_name, '') as team_name",
 		"COUNT(prr.pull_request_id) as assigned_review_count",
 		"COUNT(CASE WHEN pr.status = 'OPEN' THEN 1 END) as open_pr_review_count",
 		"COUNT(CASE WHEN pr.status = 'MERGED' THEN 1 END) as merged_pr_review_count",
+		"COALESCE(u.max_open_reviews, t.max_open_reviews) as capacity",
+		"(SELECT COUNT(*) FROM pull_request_reviewers lr JOIN pull_requests lp ON lp.pull_request_id = lr.pull_request_id "+
+			"WHERE lr.reviewer_id = u.user_id AND lp.status = 'OPEN') as current_load",
 	).
 		From("users u").
-		LeftJoin("pull_request_reviewers prr ON u.user_id = prr.reviewer_id").
-		LeftJoin("pull_requests pr ON prr.pull_request_id = pr.pull_request_id").
-		GroupBy("u.user_id", "u.username", "u.team_name").
-		OrderBy("assigned_review_count DESC").
-		PlaceholderFormat(sq.Dollar).
-		ToSql()
+		LeftJoin("teams t ON u.team_name = t.team_name").
+		LeftJoin("("+reviewJoin+") ON u.user_id = prr.reviewer_id", prArgs...).
+		GroupBy("u.user_id", "u.username", "u.team_name", "t.max_open_reviews").
+		OrderBy("assigned_review_count DESC")
+
+	if filter.TeamName != "" {
+		builder = builder.Where(sq.Or{sq.Eq{"u.team_name": filter.TeamName}, sq.NotEq{"prr.reviewer_id": nil}})
+	}
+
+	query, args, err := builder.PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
@@ -68,8 +118,10 @@ func (r *Repository) GetPullRequestStats(ctx context.Context) ([]domain.PullRequ
 		var assignedPRCount int
 		var open int
 		var merged int
+		var capacity *int
+		var currentLoad int
 
-		if err = rows.Scan(&userID, &userName, &teamName, &assignedPRCount, &open, &merged); err != nil {
+		if err = rows.Scan(&userID, &userName, &teamName, &assignedPRCount, &open, &merged, &capacity, &currentLoad); err != nil {
 			return fail(domain.INTERNAL, "internal server error", err)
 		}
 
@@ -80,6 +132,8 @@ func (r *Repository) GetPullRequestStats(ctx context.Context) ([]domain.PullRequ
 			AssignedPRCount: assignedPRCount,
 			Open:            open,
 			Merged:          merged,
+			Capacity:        capacity,
+			CurrentLoad:     currentLoad,
 		}
 		result = append(result, pr)
 	}
@@ -94,3 +148,87 @@ func (r *Repository) GetPullRequestStats(ctx context.Context) ([]domain.PullRequ
 
 	return result, nil
 }
+
+// GetTeamStats aggregates pull requests authored and reviews done for the team among
+// the pull requests matching the filter. Both are attributed to the team the user was
+// in at the time: the author's team when the pull request was created and the
+// reviewer's team when assigned, so moving or deleting users does not change the
+// history. Pull requests and reviews whose team was deleted belong to no team.
+func (r *Repository) GetTeamStats(ctx context.Context, filter domain.StatsFilter) ([]domain.TeamStats, error) {
+	const op = "stats.Repository.GetTeamStats"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]domain.TeamStats, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	authored, authoredArgs, err := sq.Select(
+		"pr.author_team_name as team_name",
+		"COUNT(*) as authored_pr_count",
+		"AVG(rc.reviewers) as avg_reviewers_per_pr",
+		"AVG(CASE WHEN pr.need_more_reviewers THEN 1 ELSE 0 END) as need_more_reviewers_share",
+	).
+		From("pull_requests pr").
+		JoinClause("CROSS JOIN LATERAL (SELECT COUNT(*) as reviewers FROM pull_request_reviewers x WHERE x.pull_request_id = pr.pull_request_id) rc").
+		Where(pullRequestConditions(filter)).
+		GroupBy("pr.author_team_name").
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	reviews, reviewsArgs, err := sq.Select(
+		"prr.reviewer_team_name as team_name",
+		"COUNT(*) as review_count",
+		"COUNT(CASE WHEN pr.status = 'MERGED' THEN 1 END) as merged_review_count",
+	).
+		From("pull_request_reviewers prr").
+		Join("pull_requests pr ON pr.pull_request_id = prr.pull_request_id").
+		Where(reviewConditions(filter)).
+		GroupBy("prr.reviewer_team_name").
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	builder := sq.Select(
+		"t.team_name",
+		"COALESCE(a.authored_pr_count, 0) as authored_pr_count",
+		"COALESCE(rv.review_count, 0) as review_count",
+		"COALESCE(rv.merged_review_count, 0) as merged_review_count",
+		"COALESCE(a.avg_reviewers_per_pr, 0)::float8 as avg_reviewers_per_pr",
+		"COALESCE(a.need_more_reviewers_share, 0)::float8 as need_more_reviewers_share",
+	).
+		From("teams t").
+		LeftJoin("("+authored+") a ON a.team_name = t.team_name", authoredArgs...).
+		LeftJoin("("+reviews+") rv ON rv.team_name = t.team_name", reviewsArgs...).
+		OrderBy("t.team_name")
+
+	if filter.TeamName != "" {
+		builder = builder.Where(sq.Eq{"t.team_name": filter.TeamName})
+	}
+
+	query, args, err := builder.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	result := []domain.TeamStats{}
+	if err = tx.SelectContext(ctx, &result, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return result, nil
+}
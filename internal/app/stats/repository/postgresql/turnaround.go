@@ -0,0 +1,153 @@
+package postgresql
+
+import (
+	"context"
+	"log"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/transaction"
+)
+
+const (
+	// timeToMergeExpr is the time from the moment the pull request last became
+	// OPEN to the merge, drafts do not count.
+	timeToMergeExpr = "EXTRACT(EPOCH FROM pr.merged_at - pr.ready_at)::float8"
+	// timeInReviewExpr is the time the reviewer spent on the pull request: from
+	// the assignment, or from reopening if the reviewer was assigned before, to
+	// the merge.
+	timeInReviewExpr = "EXTRACT(EPOCH FROM pr.merged_at - GREATEST(prr.assigned_at, pr.ready_at))::float8"
+)
+
+func percentileColumns(expr string) []string {
+	return []string{
+		"COUNT(*) as merged_count",
+		"percentile_cont(0.5) WITHIN GROUP (ORDER BY " + expr + ") as p50",
+		"percentile_cont(0.9) WITHIN GROUP (ORDER BY " + expr + ") as p90",
+		"percentile_cont(0.99) WITHIN GROUP (ORDER BY " + expr + ") as p99",
+	}
+}
+
+// mergedConditions restricts pull requests aliased as pr to merged ones with both
+// timestamps, merged in the filter window.
+func mergedConditions(filter domain.StatsFilter) sq.And {
+	conditions := sq.And{
+		sq.Eq{"pr.status": domain.MERGED},
+		sq.NotEq{"pr.ready_at": nil},
+		sq.NotEq{"pr.merged_at": nil},
+	}
+	if filter.From != nil {
+		conditions = append(conditions, sq.GtOrEq{"pr.merged_at": *filter.From})
+	}
+	if filter.To != nil {
+		conditions = append(conditions, sq.Lt{"pr.merged_at": *filter.To})
+	}
+	return conditions
+}
+
+// GetTimeToMerge returns time to merge percentiles per author's team or author and
+// time in review percentiles per reviewer, slowest first. Like team statistics,
+// pull requests are attributed to the author's team at creation and reviews to the
+// reviewer's team when assigned, so the team filter applies to the latter when
+// grouping by reviewer.
+func (r *Repository) GetTimeToMerge(ctx context.Context, filter domain.StatsFilter, groupBy domain.TimeToMergeGroup) ([]domain.TimeToMergeStats, error) {
+	const op = "stats.Repository.GetTimeToMerge"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]domain.TimeToMergeStats, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	key, teamColumn, expr := "pr.author_team_name", "pr.author_team_name", timeToMergeExpr
+	switch groupBy {
+	case domain.GROUP_BY_AUTHOR:
+		key = "pr.author_id"
+	case domain.GROUP_BY_REVIEWER:
+		key, teamColumn, expr = "prr.reviewer_id", "prr.reviewer_team_name", timeInReviewExpr
+	}
+
+	builder := sq.Select(append([]string{key + " as group_key"}, percentileColumns(expr)...)...).
+		From("pull_requests pr").
+		Where(mergedConditions(filter)).
+		Where(sq.NotEq{key: nil}).
+		GroupBy(key).
+		OrderBy("p50 DESC", "group_key")
+
+	if groupBy == domain.GROUP_BY_REVIEWER {
+		builder = builder.Join("pull_request_reviewers prr ON prr.pull_request_id = pr.pull_request_id")
+	}
+	if filter.TeamName != "" {
+		builder = builder.Where(sq.Eq{teamColumn: filter.TeamName})
+	}
+
+	query, args, err := builder.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	result := []domain.TimeToMergeStats{}
+	if err = tx.SelectContext(ctx, &result, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return result, nil
+}
+
+// GetTimeToMergeDaily returns time to merge percentiles of pull requests merged on
+// each UTC day, days without merges are skipped.
+func (r *Repository) GetTimeToMergeDaily(ctx context.Context, filter domain.StatsFilter) ([]domain.TimeToMergeDay, error) {
+	const op = "stats.Repository.GetTimeToMergeDaily"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]domain.TimeToMergeDay, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	const dayExpr = "to_char(pr.merged_at AT TIME ZONE 'UTC', 'YYYY-MM-DD')"
+
+	builder := sq.Select(append([]string{dayExpr + " as day"}, percentileColumns(timeToMergeExpr)...)...).
+		From("pull_requests pr").
+		Where(mergedConditions(filter)).
+		GroupBy(dayExpr).
+		OrderBy("day")
+
+	if filter.TeamName != "" {
+		builder = builder.Where(sq.Eq{"pr.author_team_name": filter.TeamName})
+	}
+
+	query, args, err := builder.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	result := []domain.TimeToMergeDay{}
+	if err = tx.SelectContext(ctx, &result, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return result, nil
+}
@@ -16,15 +16,63 @@ func NewUsecase(sRepository stats.Repository) *Usecase {
 	return &Usecase{StatsRepository: sRepository}
 }
 
-func (u *Usecase) GetPullRequestStats(ctx context.Context) ([]domain.PullRequestStats, error) {
+func (u *Usecase) GetPullRequestStats(ctx context.Context, filter domain.StatsFilter) ([]domain.PullRequestStats, error) {
 	const op = "users.Usecase.GetPullRequestStats"
 
 	fail := func(code domain.ErrorCode, message string, err error) ([]domain.PullRequestStats, error) {
-		log.Printf("%s, %v\n", op, err)
+		log.Printf("%s: %v\n", op, err)
 		return nil, domain.NewError(code, message, err)
 	}
 
-	stats, err := u.StatsRepository.GetPullRequestStats(ctx)
+	stats, err := u.StatsRepository.GetPullRequestStats(ctx, filter)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return stats, nil
+}
+
+func (u *Usecase) GetTeamStats(ctx context.Context, filter domain.StatsFilter) ([]domain.TeamStats, error) {
+	const op = "stats.Usecase.GetTeamStats"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]domain.TeamStats, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	stats, err := u.StatsRepository.GetTeamStats(ctx, filter)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return stats, nil
+}
+
+func (u *Usecase) GetTimeToMerge(ctx context.Context, filter domain.StatsFilter, groupBy domain.TimeToMergeGroup) ([]domain.TimeToMergeStats, error) {
+	const op = "stats.Usecase.GetTimeToMerge"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]domain.TimeToMergeStats, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	stats, err := u.StatsRepository.GetTimeToMerge(ctx, filter, groupBy)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return stats, nil
+}
+
+func (u *Usecase) GetTimeToMergeDaily(ctx context.Context, filter domain.StatsFilter) ([]domain.TimeToMergeDay, error) {
+	const op = "stats.Usecase.GetTimeToMergeDaily"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]domain.TimeToMergeDay, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	stats, err := u.StatsRepository.GetTimeToMergeDaily(ctx, filter)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
@@ -10,6 +10,8 @@ import (
 type Controller interface {
 	AddTeam(w http.ResponseWriter, r *http.Request)
 	GetTeam(w http.ResponseWriter, r *http.Request)
+	UpdateTeam(w http.ResponseWriter, r *http.Request)
+	DeactivateMembers(w http.ResponseWriter, r *http.Request)
 
 	SetupRoutes(r chi.Router, cfg *config.Config)
 }
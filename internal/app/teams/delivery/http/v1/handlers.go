@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/leoscrowi/pr-assignment-service/domain"
@@ -33,6 +34,7 @@ func (c *TeamsController) GetTeam(w http.ResponseWriter, r *http.Request) {
 		domain.WriteError(w, domain.ConvertToErrorResponse(err))
 		return
 	}
+	utils.SetETag(w, team.Version)
 	utils.WriteHeader(w, http.StatusOK, &team)
 }
 
@@ -43,7 +45,7 @@ func (c *TeamsController) AddTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if team.TeamName == "" || len(team.Members) == 0 {
+	if team.TeamName == "" || len(team.Members) == 0 || team.RequiredReviewers < 0 || !domain.ValidMaxOpenReviews(team.MaxOpenReviews) {
 		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
 		return
 	}
@@ -57,3 +59,161 @@ func (c *TeamsController) AddTeam(w http.ResponseWriter, r *http.Request) {
 	var resp = dtos.AddTeamResponse{Team: newTeam}
 	utils.WriteHeader(w, http.StatusCreated, &resp)
 }
+
+func (c *TeamsController) UpdateTeam(w http.ResponseWriter, r *http.Request) {
+	var req dtos.UpdateTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	if req.TeamName == "" || (req.RequiredReviewers != nil && *req.RequiredReviewers < 0) || !domain.ValidMaxOpenReviews(req.MaxOpenReviews.Value) {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
+		return
+	}
+
+	team, err := c.usecase.UpdateTeam(r.Context(), req.TeamName, domain.TeamSettings{
+		RequiredReviewers: req.RequiredReviewers,
+		MaxOpenReviews:    req.MaxOpenReviews,
+		FallbackTeams:     req.FallbackTeams,
+	}, utils.IfMatchVersion(r))
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.UpdateTeamResponse{Team: team}
+	utils.SetETag(w, team.Version)
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func (c *TeamsController) DeactivateMembers(w http.ResponseWriter, r *http.Request) {
+	var req dtos.DeactivateMembersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	if req.TeamName == "" {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
+		return
+	}
+
+	result, err := c.usecase.DeactivateMembers(r.Context(), req.TeamName, req.UserIDs)
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.DeactivateMembersResponse{Result: result}
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func (c *TeamsController) ListTeams(w http.ResponseWriter, r *http.Request) {
+	teams, err := c.usecase.ListTeams(r.Context())
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.ListTeamsResponse{Teams: teams}
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func (c *TeamsController) RenameTeam(w http.ResponseWriter, r *http.Request) {
+	var req dtos.RenameTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	if req.TeamName == "" || req.NewTeamName == "" {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
+		return
+	}
+
+	team, err := c.usecase.RenameTeam(r.Context(), req.TeamName, req.NewTeamName, utils.IfMatchVersion(r))
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.RenameTeamResponse{Team: team}
+	utils.SetETag(w, team.Version)
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func (c *TeamsController) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	teamName := chi.URLParam(r, "team_name")
+
+	if teamName == "" {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
+		return
+	}
+
+	result, err := c.usecase.DeleteTeam(r.Context(), teamName, r.URL.Query().Get("move_members_to"))
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.RemoveMembersResponse{Result: result}
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func (c *TeamsController) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	teamName := chi.URLParam(r, "team_name")
+	userID := chi.URLParam(r, "user_id")
+
+	if teamName == "" || userID == "" {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
+		return
+	}
+
+	result, err := c.usecase.RemoveMember(r.Context(), teamName, userID)
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.RemoveMembersResponse{Result: result}
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func (c *TeamsController) SyncTeam(w http.ResponseWriter, r *http.Request) {
+	var req dtos.SyncTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	if req.TeamName == "" || len(req.Members) == 0 {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
+		return
+	}
+
+	for _, member := range req.Members {
+		if member.UserID == "" || member.UserName == "" {
+			domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
+			return
+		}
+	}
+
+	dryRun := false
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+			return
+		}
+	}
+
+	sync, err := c.usecase.SyncTeam(r.Context(), req.TeamName, req.Members, dryRun)
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.SyncTeamResponse{Sync: sync}
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
@@ -10,5 +10,13 @@ func (c *TeamsController) SetupRoutes(r chi.Router, cfg *config.Config) {
 	r.Route("/team", func(r chi.Router) {
 		r.With(middleware.AuthMiddleware(cfg)).Get("/get/{team_name}", c.GetTeam)
 		r.Post("/add", c.AddTeam)
+		r.With(middleware.AdminMiddleware(cfg)).Patch("/update", c.UpdateTeam)
+		r.With(middleware.AdminMiddleware(cfg)).Post("/deactivateMembers", c.DeactivateMembers)
+
+		r.With(middleware.AdminMiddleware(cfg)).Get("/list", c.ListTeams)
+		r.With(middleware.AdminMiddleware(cfg)).Put("/sync", c.SyncTeam)
+		r.With(middleware.AdminMiddleware(cfg)).Patch("/rename", c.RenameTeam)
+		r.With(middleware.AdminMiddleware(cfg)).Delete("/delete/{team_name}", c.DeleteTeam)
+		r.With(middleware.AdminMiddleware(cfg)).Delete("/removeMember/{team_name}/{user_id}", c.RemoveMember)
 	})
 }
@@ -9,3 +9,49 @@ type GetTeamRequest struct {
 type AddTeamResponse struct {
 	Team domain.Team `json:"team"`
 }
+
+type UpdateTeamRequest struct {
+	TeamName          string               `json:"team_name"`
+	RequiredReviewers *int                 `json:"required_reviewers"`
+	MaxOpenReviews    domain.Optional[int] `json:"max_open_reviews"`
+	FallbackTeams     *[]string            `json:"fallback_teams"`
+}
+
+type UpdateTeamResponse struct {
+	Team domain.Team `json:"team"`
+}
+
+type DeactivateMembersRequest struct {
+	TeamName string   `json:"team_name"`
+	UserIDs  []string `json:"user_ids"`
+}
+
+type DeactivateMembersResponse struct {
+	Result domain.TeamDeactivation `json:"result"`
+}
+
+type ListTeamsResponse struct {
+	Teams []domain.TeamSummary `json:"teams"`
+}
+
+type RenameTeamRequest struct {
+	TeamName    string `json:"team_name"`
+	NewTeamName string `json:"new_team_name"`
+}
+
+type RenameTeamResponse struct {
+	Team domain.Team `json:"team"`
+}
+
+type RemoveMembersResponse struct {
+	Result domain.TeamMembersRemoval `json:"result"`
+}
+
+type SyncTeamRequest struct {
+	TeamName string              `json:"team_name"`
+	Members  []domain.TeamMember `json:"members"`
+}
+
+type SyncTeamResponse struct {
+	Sync domain.TeamSync `json:"sync"`
+}
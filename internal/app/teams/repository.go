@@ -8,5 +8,14 @@ import (
 
 type Repository interface {
 	CreateTeam(ctx context.Context, team *domain.Team) error
+	CreateTeamIfNotExists(ctx context.Context, team *domain.Team) (bool, error)
 	FetchTeamByName(ctx context.Context, teamName string) (domain.Team, error)
+	UpdateTeam(ctx context.Context, team *domain.Team) error
+	SetFallbackTeams(ctx context.Context, teamName string, fallbackTeams []string) error
+	LockTeam(ctx context.Context, teamName string) error
+	LockTeams(ctx context.Context, teamNames []string) error
+	ListTeams(ctx context.Context) ([]domain.TeamSummary, error)
+	FetchDependentTeams(ctx context.Context, teamName string) ([]string, error)
+	RenameTeam(ctx context.Context, teamName, newTeamName string) error
+	DeleteTeam(ctx context.Context, teamName string) error
 }
@@ -2,14 +2,22 @@ package postgresql
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"log"
+	"slices"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
 	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/transaction"
+	"github.com/lib/pq"
 )
 
-const tableName = "teams"
+const (
+	tableName          = "teams"
+	fallbacksTableName = "team_fallbacks"
+)
 
 type Repository struct {
 	db *sqlx.DB
@@ -27,21 +35,39 @@ func (r *Repository) FetchTeamByName(ctx context.Context, teamName string) (doma
 		return domain.Team{}, domain.NewError(code, message, err)
 	}
 
-	tx, err := r.db.BeginTxx(ctx, nil)
+	tx, err := transaction.Begin(ctx, r.db)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	defer func(tx *sqlx.Tx) {
+	defer func(tx *transaction.Tx) {
 		_ = tx.Rollback()
 	}(tx)
 
-	query, args, err := sq.Select("team_name").From(tableName).Where(sq.Eq{"team_name": teamName}).PlaceholderFormat(sq.Dollar).ToSql()
+	query, args, err := sq.Select("team_name", "required_reviewers", "max_open_reviews", "version").From(tableName).Where(sq.Eq{"team_name": teamName}).PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
 	var team domain.Team
 	if err = tx.GetContext(ctx, &team, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fail(domain.NOT_FOUND, "resource not found", err)
+		}
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	query, args, err = sq.Select("fallback_team_name").
+		From(fallbacksTableName).
+		Where(sq.Eq{"team_name": teamName}).
+		OrderBy("position").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	team.FallbackTeams = []string{}
+	if err = tx.SelectContext(ctx, &team.FallbackTeams, query, args...); err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
@@ -60,17 +86,17 @@ func (r *Repository) CreateTeam(ctx context.Context, team *domain.Team) error {
 		return domain.NewError(code, message, err)
 	}
 
-	tx, err := r.db.BeginTxx(ctx, nil)
+	tx, err := transaction.Begin(ctx, r.db)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	defer func(tx *sqlx.Tx) {
+	defer func(tx *transaction.Tx) {
 		_ = tx.Rollback()
 	}(tx)
 
 	query, args, err := sq.Insert(tableName).
-		Columns("team_name").
-		Values(team.TeamName).
+		Columns("team_name", "required_reviewers", "max_open_reviews").
+		Values(team.TeamName, team.RequiredReviewers, team.MaxOpenReviews).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 	if err != nil {
@@ -82,9 +108,445 @@ func (r *Repository) CreateTeam(ctx context.Context, team *domain.Team) error {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
+	if err = insertFallbacks(ctx, tx, team.TeamName, team.FallbackTeams); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "can't commit the transaction", err)
+	}
+
+	return nil
+}
+
+// CreateTeamIfNotExists creates the team without fallbacks unless it exists and
+// reports whether it was created. A concurrent creation of the same team waits
+// for the other transaction instead of failing.
+func (r *Repository) CreateTeamIfNotExists(ctx context.Context, team *domain.Team) (bool, error) {
+	const op = "teams.Repository.CreateTeamIfNotExists"
+
+	fail := func(code domain.ErrorCode, message string, err error) (bool, error) {
+		log.Printf("%s: %v\n", op, err)
+		return false, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Insert(tableName).
+		Columns("team_name", "required_reviewers", "max_open_reviews").
+		Values(team.TeamName, team.RequiredReviewers, team.MaxOpenReviews).
+		Suffix("ON CONFLICT (team_name) DO NOTHING").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	created, err := res.RowsAffected()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "can't commit the transaction", err)
+	}
+
+	return created > 0, nil
+}
+
+func (r *Repository) UpdateTeam(ctx context.Context, team *domain.Team) error {
+	const op = "teams.Repository.UpdateTeam"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Update(tableName).
+		Set("required_reviewers", team.RequiredReviewers).
+		Set("max_open_reviews", team.MaxOpenReviews).
+		Where(sq.Eq{"team_name": team.TeamName}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	if affected == 0 {
+		return fail(domain.NOT_FOUND, "resource not found", nil)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "can't commit the transaction", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) SetFallbackTeams(ctx context.Context, teamName string, fallbackTeams []string) error {
+	const op = "teams.Repository.SetFallbackTeams"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Delete(fallbacksTableName).
+		Where(sq.Eq{"team_name": teamName}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = insertFallbacks(ctx, tx, teamName, fallbackTeams); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "can't commit the transaction", err)
+	}
+
+	return nil
+}
+
+func insertFallbacks(ctx context.Context, tx *transaction.Tx, teamName string, fallbackTeams []string) error {
+	if len(fallbackTeams) == 0 {
+		return nil
+	}
+
+	builder := sq.Insert(fallbacksTableName).
+		Columns("team_name", "fallback_team_name", "position")
+	for position, fallbackTeam := range fallbackTeams {
+		builder = builder.Values(teamName, fallbackTeam, position)
+	}
+
+	query, args, err := builder.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+// LockTeam locks the team row until the surrounding transaction ends, so that
+// reviewers of the team are assigned one request at a time.
+func (r *Repository) LockTeam(ctx context.Context, teamName string) error {
+	const op = "teams.Repository.LockTeam"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Select("team_name").
+		From(tableName).
+		Where(sq.Eq{"team_name": teamName}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	var locked string
+	if err = tx.GetContext(ctx, &locked, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fail(domain.NOT_FOUND, "resource not found", err)
+		}
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
+}
+
+// LockTeams locks the team rows in team_name order, the order every transaction
+// takes several team locks in, so that two of them never wait for each other
+// crosswise. All teams must exist, empty names of users without a team are skipped.
+func (r *Repository) LockTeams(ctx context.Context, teamNames []string) error {
+	const op = "teams.Repository.LockTeams"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	names := slices.Clone(teamNames)
+	slices.Sort(names)
+	names = slices.DeleteFunc(slices.Compact(names), func(name string) bool { return name == "" })
+	if len(names) == 0 {
+		return nil
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Select("team_name").
+		From(tableName).
+		Where(sq.Eq{"team_name": names}).
+		OrderBy("team_name").
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	var locked []string
+	if err = tx.SelectContext(ctx, &locked, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	if len(locked) != len(names) {
+		return fail(domain.NOT_FOUND, "resource not found", nil)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) ListTeams(ctx context.Context) ([]domain.TeamSummary, error) {
+	const op = "teams.Repository.ListTeams"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]domain.TeamSummary, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Select(
+		"t.team_name",
+		"t.required_reviewers",
+		"t.max_open_reviews",
+		"t.version",
+		"COUNT(u.user_id) as member_count",
+		"COUNT(u.user_id) FILTER (WHERE u.is_active) as active_member_count",
+	).
+		From(tableName + " t").
+		LeftJoin("users u ON u.team_name = t.team_name").
+		GroupBy("t.team_name").
+		OrderBy("t.team_name").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	result := []domain.TeamSummary{}
+	if err = tx.SelectContext(ctx, &result, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return result, nil
+}
+
+// FetchDependentTeams returns the teams using the team as a fallback.
+func (r *Repository) FetchDependentTeams(ctx context.Context, teamName string) ([]string, error) {
+	const op = "teams.Repository.FetchDependentTeams"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]string, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Select("team_name").
+		From(fallbacksTableName).
+		Where(sq.Eq{"fallback_team_name": teamName}).
+		OrderBy("team_name").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	result := []string{}
+	if err = tx.SelectContext(ctx, &result, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return result, nil
+}
+
+// RenameTeam changes the team name, members and fallbacks follow it through
+// ON UPDATE CASCADE.
+func (r *Repository) RenameTeam(ctx context.Context, teamName, newTeamName string) error {
+	const op = "teams.Repository.RenameTeam"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Update(tableName).
+		Set("team_name", newTeamName).
+		Where(sq.Eq{"team_name": teamName}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fail(domain.TEAM_EXISTS, "team already exists", err)
+		}
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	if affected == 0 {
+		return fail(domain.NOT_FOUND, "resource not found", nil)
+	}
+
 	if err = tx.Commit(); err != nil {
 		return fail(domain.INTERNAL, "can't commit the transaction", err)
 	}
 
 	return nil
 }
+
+func (r *Repository) DeleteTeam(ctx context.Context, teamName string) error {
+	const op = "teams.Repository.DeleteTeam"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Delete(tableName).
+		Where(sq.Eq{"team_name": teamName}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	if affected == 0 {
+		return fail(domain.NOT_FOUND, "resource not found", nil)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "can't commit the transaction", err)
+	}
+
+	return nil
+}
+
+// isUniqueViolation reports whether the statement hit a unique or primary key
+// constraint.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -9,4 +9,11 @@ import (
 type Usecase interface {
 	GetTeam(ctx context.Context, teamName string) (domain.Team, error)
 	AddTeam(ctx context.Context, team *domain.Team) (domain.Team, error)
+	UpdateTeam(ctx context.Context, teamName string, settings domain.TeamSettings, expectedVersion *int64) (domain.Team, error)
+	ListTeams(ctx context.Context) ([]domain.TeamSummary, error)
+	RenameTeam(ctx context.Context, teamName, newTeamName string, expectedVersion *int64) (domain.Team, error)
+	DeleteTeam(ctx context.Context, teamName string, moveMembersTo string) (domain.TeamMembersRemoval, error)
+	RemoveMember(ctx context.Context, teamName, userID string) (domain.TeamMembersRemoval, error)
+	SyncTeam(ctx context.Context, teamName string, members []domain.TeamMember, dryRun bool) (domain.TeamSync, error)
+	DeactivateMembers(ctx context.Context, teamName string, userIDs []string) (domain.TeamDeactivation, error)
 }
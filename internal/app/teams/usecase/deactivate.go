@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/app/pull_requests"
+)
+
+// DeactivateMembers deactivates the given members of the team, or the whole team
+// if userIDs is empty, and reassigns their OPEN reviews in the same transaction.
+func (u *Usecase) DeactivateMembers(ctx context.Context, teamName string, userIDs []string) (domain.TeamDeactivation, error) {
+	var result domain.TeamDeactivation
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = u.deactivateMembers(ctx, teamName, userIDs)
+		return err
+	})
+	return result, err
+}
+
+func (u *Usecase) deactivateMembers(ctx context.Context, teamName string, userIDs []string) (domain.TeamDeactivation, error) {
+	const op = "teams.Usecase.DeactivateMembers"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.TeamDeactivation, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.TeamDeactivation{}, domain.NewError(code, message, err)
+	}
+
+	members, err := u.UsersRepository.FetchByTeamName(ctx, teamName)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	affected := userIDs
+	if len(userIDs) == 0 {
+		for _, member := range members {
+			affected = append(affected, member.UserID)
+		}
+	}
+
+	// Their pull requests are locked before the team, see LockForChange.
+	scope := pull_requests.LockScope{UserIDs: affected, TeamNames: []string{teamName}}
+	if err = u.Locker.LockForChange(ctx, scope); err != nil {
+		if domain.HasCode(err, domain.NOT_FOUND) {
+			return fail(domain.NOT_FOUND, "resource not found", err)
+		}
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if len(userIDs) > 0 {
+		isMember := make(map[string]bool, len(members))
+		for _, member := range members {
+			isMember[member.UserID] = true
+		}
+
+		for _, userID := range userIDs {
+			if !isMember[userID] {
+				return fail(domain.NOT_FOUND, fmt.Sprintf("user %s is not a member of team %s", userID, teamName), nil)
+			}
+		}
+	}
+
+	deactivated, err := u.UsersRepository.SetTeamMembersActive(ctx, teamName, userIDs, false)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	reassignments, err := u.ReviewerReleaser.ReleaseReviewers(ctx, deactivated)
+	if err != nil {
+		return fail(domain.INTERNAL, "failed to reassign reviews", err)
+	}
+
+	return domain.TeamDeactivation{
+		TeamName:         teamName,
+		DeactivatedUsers: deactivated,
+		Reassignments:    reassignments,
+	}, nil
+}
@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/app/pull_requests"
+)
+
+func (u *Usecase) ListTeams(ctx context.Context) ([]domain.TeamSummary, error) {
+	const op = "teams.Usecase.ListTeams"
+
+	teams, err := u.TeamsRepository.ListTeams(ctx)
+	if err != nil {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(domain.INTERNAL, "internal server error", err)
+	}
+
+	return teams, nil
+}
+
+func (u *Usecase) RenameTeam(ctx context.Context, teamName, newTeamName string, expectedVersion *int64) (domain.Team, error) {
+	var renamed domain.Team
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		renamed, err = u.renameTeam(ctx, teamName, newTeamName, expectedVersion)
+		return err
+	})
+	return renamed, err
+}
+
+func (u *Usecase) renameTeam(ctx context.Context, teamName, newTeamName string, expectedVersion *int64) (domain.Team, error) {
+	const op = "teams.Usecase.RenameTeam"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.Team, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.Team{}, domain.NewError(code, message, err)
+	}
+
+	// The rename cascades to every pull request attributed to the team, so those
+	// are locked before the team, see LockForChange.
+	scope := pull_requests.LockScope{AuthorTeams: []string{teamName}, TeamNames: []string{teamName}}
+	if err := u.Locker.LockForChange(ctx, scope); err != nil {
+		if domain.HasCode(err, domain.NOT_FOUND) {
+			return fail(domain.NOT_FOUND, "resource not found", err)
+		}
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	team, err := u.TeamsRepository.FetchTeamByName(ctx, teamName)
+	if err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	if !domain.VersionMatches(expectedVersion, team.Version) {
+		return fail(domain.PRECONDITION_FAILED, "team was modified", nil)
+	}
+
+	if newTeamName == teamName {
+		return u.GetTeam(ctx, teamName)
+	}
+
+	// Only a missing team means the name is free, any other failure is reported
+	// as is. A team taken concurrently still fails on the primary key below.
+	_, err = u.TeamsRepository.FetchTeamByName(ctx, newTeamName)
+	switch {
+	case err == nil:
+		return fail(domain.TEAM_EXISTS, fmt.Sprintf("%s already exists", newTeamName), nil)
+	case !domain.HasCode(err, domain.NOT_FOUND):
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = u.TeamsRepository.RenameTeam(ctx, teamName, newTeamName); err != nil {
+		if domain.HasCode(err, domain.TEAM_EXISTS) {
+			return fail(domain.TEAM_EXISTS, fmt.Sprintf("%s already exists", newTeamName), err)
+		}
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return u.GetTeam(ctx, newTeamName)
+}
+
+// DeleteTeam deletes the team. With moveMembersTo set its members are moved to
+// that team together with their reviews and pull requests, otherwise they are
+// removed as by RemoveMember.
+func (u *Usecase) DeleteTeam(ctx context.Context, teamName string, moveMembersTo string) (domain.TeamMembersRemoval, error) {
+	const op = "teams.Usecase.DeleteTeam"
+
+	var result domain.TeamMembersRemoval
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = u.deleteTeam(ctx, teamName, moveMembersTo)
+		return err
+	})
+	if err != nil {
+		return domain.TeamMembersRemoval{}, err
+	}
+
+	if len(result.MovedUsers) > 0 {
+		if _, err = u.Backfiller.BackfillPullRequests(ctx, moveMembersTo); err != nil {
+			log.Printf("%s: backfill after moving members: %v\n", op, err)
+		}
+	}
+
+	return result, nil
+}
+
+func (u *Usecase) deleteTeam(ctx context.Context, teamName string, moveMembersTo string) (domain.TeamMembersRemoval, error) {
+	const op = "teams.Usecase.DeleteTeam"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.TeamMembersRemoval, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.TeamMembersRemoval{}, domain.NewError(code, message, err)
+	}
+
+	if moveMembersTo == teamName {
+		return fail(domain.BAD_REQUEST, "members cannot be moved to the deleted team", nil)
+	}
+
+	memberIDs, err := u.memberIDs(ctx, teamName)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	// The deletion also touches the teams using this one as a fallback.
+	dependents, err := u.TeamsRepository.FetchDependentTeams(ctx, teamName)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	// Pull requests of the members and those attributed to the team are locked
+	// first, then all teams in name order, so that two deletions moving members
+	// crosswise do not wait for each other.
+	scope := pull_requests.LockScope{UserIDs: memberIDs, AuthorTeams: []string{teamName}, TeamNames: append(dependents, teamName)}
+	if moveMembersTo != "" {
+		scope.TeamNames = append(scope.TeamNames, moveMembersTo)
+	}
+	if err = u.Locker.LockForChange(ctx, scope); err != nil {
+		if domain.HasCode(err, domain.NOT_FOUND) {
+			return fail(domain.NOT_FOUND, "resource not found", err)
+		}
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if memberIDs, err = u.memberIDs(ctx, teamName); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	result := domain.TeamMembersRemoval{
+		TeamName:           teamName,
+		RemovedUsers:       []string{},
+		ClosedPullRequests: []string{},
+		Reassignments:      []domain.ReassignmentResult{},
+	}
+
+	if moveMembersTo != "" {
+		if err = u.UsersRepository.SetUsersTeam(ctx, memberIDs, &moveMembersTo); err != nil {
+			return fail(domain.INTERNAL, "internal server error", err)
+		}
+
+		result.MovedUsers = memberIDs
+		result.MovedTo = moveMembersTo
+	} else {
+		if result, err = u.removeMembers(ctx, teamName, memberIDs); err != nil {
+			return domain.TeamMembersRemoval{}, err
+		}
+	}
+
+	if err = u.TeamsRepository.DeleteTeam(ctx, teamName); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return result, nil
+}
+
+// RemoveMember takes the user out of the team: the user is deactivated, their
+// OPEN and DRAFT pull requests are closed and OPEN reviews are reassigned.
+func (u *Usecase) RemoveMember(ctx context.Context, teamName, userID string) (domain.TeamMembersRemoval, error) {
+	var result domain.TeamMembersRemoval
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = u.removeMember(ctx, teamName, userID)
+		return err
+	})
+	return result, err
+}
+
+func (u *Usecase) removeMember(ctx context.Context, teamName, userID string) (domain.TeamMembersRemoval, error) {
+	const op = "teams.Usecase.RemoveMember"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.TeamMembersRemoval, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.TeamMembersRemoval{}, domain.NewError(code, message, err)
+	}
+
+	scope := pull_requests.LockScope{UserIDs: []string{userID}, TeamNames: []string{teamName}}
+	if err := u.Locker.LockForChange(ctx, scope); err != nil {
+		if domain.HasCode(err, domain.NOT_FOUND) {
+			return fail(domain.NOT_FOUND, "resource not found", err)
+		}
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	user, err := u.UsersRepository.FetchByID(ctx, userID)
+	if err != nil {
+		return fail(domain.NOT_FOUND, "user not found", err)
+	}
+
+	if user.TeamName != teamName {
+		return fail(domain.NOT_FOUND, fmt.Sprintf("user %s is not a member of team %s", userID, teamName), nil)
+	}
+
+	return u.removeMembers(ctx, teamName, []string{userID})
+}
+
+// removeMembers expects the pull requests of the users and the team to be locked
+// by the caller.
+func (u *Usecase) removeMembers(ctx context.Context, teamName string, userIDs []string) (domain.TeamMembersRemoval, error) {
+	const op = "teams.Usecase.removeMembers"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.TeamMembersRemoval, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.TeamMembersRemoval{}, domain.NewError(code, message, err)
+	}
+
+	result := domain.TeamMembersRemoval{
+		TeamName:           teamName,
+		RemovedUsers:       userIDs,
+		ClosedPullRequests: []string{},
+		Reassignments:      []domain.ReassignmentResult{},
+	}
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	if _, err := u.UsersRepository.SetTeamMembersActive(ctx, teamName, userIDs, false); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	closed, err := u.AuthorReleaser.CloseAuthoredPullRequests(ctx, userIDs)
+	if err != nil {
+		return fail(domain.INTERNAL, "failed to close pull requests", err)
+	}
+	result.ClosedPullRequests = closed
+
+	reassignments, err := u.ReviewerReleaser.ReleaseReviewers(ctx, userIDs)
+	if err != nil {
+		return fail(domain.INTERNAL, "failed to reassign reviews", err)
+	}
+	result.Reassignments = reassignments
+
+	if err = u.UsersRepository.SetUsersTeam(ctx, userIDs, nil); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return result, nil
+}
+
+func (u *Usecase) memberIDs(ctx context.Context, teamName string) ([]string, error) {
+	members, err := u.UsersRepository.FetchByTeamName(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	memberIDs := make([]string, 0, len(members))
+	for _, member := range members {
+		memberIDs = append(memberIDs, member.UserID)
+	}
+	return memberIDs, nil
+}
@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/app/pull_requests"
+)
+
+// SyncTeam brings the team roster in line with the desired members: missing users
+// are created, users from other teams are moved in, members not in the list are
+// removed as by RemoveMember and is_active is updated. The team is created if it
+// does not exist. With dryRun set only the difference is computed.
+func (u *Usecase) SyncTeam(ctx context.Context, teamName string, members []domain.TeamMember, dryRun bool) (domain.TeamSync, error) {
+	const op = "teams.Usecase.SyncTeam"
+
+	var result domain.TeamSync
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = u.syncTeam(ctx, teamName, members, dryRun)
+		return err
+	})
+	if err != nil {
+		return domain.TeamSync{}, err
+	}
+
+	if !dryRun && (len(result.Added) > 0 || len(result.Moved) > 0 || len(result.Activated) > 0) {
+		if _, err = u.Backfiller.BackfillPullRequests(ctx, teamName); err != nil {
+			log.Printf("%s: backfill after sync: %v\n", op, err)
+		}
+	}
+
+	return result, nil
+}
+
+func (u *Usecase) syncTeam(ctx context.Context, teamName string, members []domain.TeamMember, dryRun bool) (domain.TeamSync, error) {
+	const op = "teams.Usecase.SyncTeam"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.TeamSync, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.TeamSync{}, domain.NewError(code, message, err)
+	}
+
+	result := domain.TeamSync{
+		TeamName:           teamName,
+		DryRun:             dryRun,
+		Added:              []string{},
+		Removed:            []string{},
+		Moved:              []domain.TeamMemberMove{},
+		Activated:          []string{},
+		Deactivated:        []string{},
+		ClosedPullRequests: []string{},
+		Reassignments:      []domain.ReassignmentResult{},
+	}
+
+	desired := make(map[string]bool, len(members))
+	for _, member := range members {
+		if member.UserID == "" || desired[member.UserID] {
+			return fail(domain.BAD_REQUEST, fmt.Sprintf("invalid member %q", member.UserID), nil)
+		}
+		desired[member.UserID] = true
+	}
+
+	// A missing team is created before it is locked, so concurrent syncs of a new
+	// team queue up on the insert instead of failing on the primary key.
+	if !dryRun {
+		team := domain.Team{TeamName: teamName, RequiredReviewers: u.DefaultRequiredReviewers, FallbackTeams: []string{}}
+		created, err := u.TeamsRepository.CreateTeamIfNotExists(ctx, &team)
+		if err != nil {
+			return fail(domain.INTERNAL, "internal server error", err)
+		}
+		result.Created = created
+	} else {
+		_, err := u.TeamsRepository.FetchTeamByName(ctx, teamName)
+		switch {
+		case domain.HasCode(err, domain.NOT_FOUND):
+			result.Created = true
+		case err != nil:
+			return fail(domain.INTERNAL, "internal server error", err)
+		}
+	}
+
+	if err := u.planSync(ctx, &result, members, desired); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	// The pull requests of the users who leave, move in or stop reviewing are
+	// locked before the team and the teams the users leave, see LockForChange.
+	// The plan is then made again under the locks.
+	scope := pull_requests.LockScope{
+		UserIDs:   slices.Concat(result.Removed, result.Deactivated),
+		TeamNames: []string{teamName},
+	}
+	for _, move := range result.Moved {
+		scope.UserIDs = append(scope.UserIDs, move.UserID)
+		scope.TeamNames = append(scope.TeamNames, move.FromTeam)
+	}
+	if err := u.Locker.LockForChange(ctx, scope); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err := u.planSync(ctx, &result, members, desired); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	removal, err := u.removeMembers(ctx, teamName, result.Removed)
+	if err != nil {
+		return domain.TeamSync{}, err
+	}
+	result.ClosedPullRequests = removal.ClosedPullRequests
+	result.Reassignments = removal.Reassignments
+
+	for _, member := range members {
+		user := domain.User{
+			UserID:   member.UserID,
+			Username: member.UserName,
+			TeamName: teamName,
+			IsActive: member.IsActive,
+		}
+		if _, err = u.UsersRepository.CreateOrUpdateUser(ctx, &user); err != nil {
+			return fail(domain.INTERNAL, "internal server error", err)
+		}
+	}
+
+	reassignments, err := u.ReviewerReleaser.ReleaseReviewers(ctx, result.Deactivated)
+	if err != nil {
+		return fail(domain.INTERNAL, "failed to reassign reviews", err)
+	}
+	result.Reassignments = append(result.Reassignments, reassignments...)
+
+	return result, nil
+}
+
+// planSync fills the roster changes of the sync in result.
+func (u *Usecase) planSync(ctx context.Context, result *domain.TeamSync, members []domain.TeamMember, desired map[string]bool) error {
+	result.Added = []string{}
+	result.Removed = []string{}
+	result.Moved = []domain.TeamMemberMove{}
+	result.Activated = []string{}
+	result.Deactivated = []string{}
+
+	current := make(map[string]domain.TeamMember)
+	teamMembers, err := u.UsersRepository.FetchByTeamName(ctx, result.TeamName)
+	if err != nil {
+		return err
+	}
+	for _, member := range teamMembers {
+		current[member.UserID] = member
+	}
+
+	for _, member := range members {
+		wasActive := false
+		if existing, ok := current[member.UserID]; ok {
+			wasActive = existing.IsActive
+		} else {
+			user, err := u.UsersRepository.FetchByID(ctx, member.UserID)
+			switch {
+			case domain.HasCode(err, domain.NOT_FOUND):
+				result.Added = append(result.Added, member.UserID)
+				continue
+			case err != nil:
+				return err
+			}
+			result.Moved = append(result.Moved, domain.TeamMemberMove{UserID: member.UserID, FromTeam: user.TeamName})
+			wasActive = user.IsActive
+		}
+
+		switch {
+		case member.IsActive && !wasActive:
+			result.Activated = append(result.Activated, member.UserID)
+		case !member.IsActive && wasActive:
+			result.Deactivated = append(result.Deactivated, member.UserID)
+		}
+	}
+
+	for _, member := range current {
+		if !desired[member.UserID] {
+			result.Removed = append(result.Removed, member.UserID)
+		}
+	}
+	slices.Sort(result.Removed)
+
+	return nil
+}
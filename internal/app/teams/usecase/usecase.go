@@ -4,20 +4,29 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/leoscrowi/pr-assignment-service/internal/app/teams"
 
 	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/app/pull_requests"
 	"github.com/leoscrowi/pr-assignment-service/internal/app/users"
+	"github.com/leoscrowi/pr-assignment-service/internal/transaction"
 )
 
 type Usecase struct {
-	UsersRepository users.Repository
-	TeamsRepository teams.Repository
+	UsersRepository          users.Repository
+	TeamsRepository          teams.Repository
+	Backfiller               pull_requests.Backfiller
+	ReviewerReleaser         pull_requests.ReviewerReleaser
+	AuthorReleaser           pull_requests.AuthorReleaser
+	Locker                   pull_requests.Locker
+	DefaultRequiredReviewers int
+	TxManager                transaction.Manager
 }
 
-func NewUsecase(uRepository users.Repository, tRepository teams.Repository) *Usecase {
-	return &Usecase{UsersRepository: uRepository, TeamsRepository: tRepository}
+func NewUsecase(uRepository users.Repository, tRepository teams.Repository, backfiller pull_requests.Backfiller, releaser pull_requests.ReviewerReleaser, authorReleaser pull_requests.AuthorReleaser, locker pull_requests.Locker, defaultRequiredReviewers int, txManager transaction.Manager) *Usecase {
+	return &Usecase{UsersRepository: uRepository, TeamsRepository: tRepository, Backfiller: backfiller, ReviewerReleaser: releaser, AuthorReleaser: authorReleaser, Locker: locker, DefaultRequiredReviewers: defaultRequiredReviewers, TxManager: txManager}
 }
 
 func (u *Usecase) GetTeam(ctx context.Context, teamName string) (domain.Team, error) {
@@ -38,6 +47,20 @@ func (u *Usecase) GetTeam(ctx context.Context, teamName string) (domain.Team, er
 		return fail(domain.NOT_FOUND, "resource not found", err)
 	}
 
+	absences, err := u.UsersRepository.FetchAbsencesByTeam(ctx, teamName, time.Now())
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	absencesByUser := make(map[string][]domain.Absence)
+	for _, absence := range absences {
+		absencesByUser[absence.UserID] = append(absencesByUser[absence.UserID], absence)
+	}
+
+	for i := range teamMembers {
+		teamMembers[i].Absences = absencesByUser[teamMembers[i].UserID]
+	}
+
 	team.Members = teamMembers
 
 	return team, nil
@@ -46,11 +69,43 @@ func (u *Usecase) GetTeam(ctx context.Context, teamName string) (domain.Team, er
 func (u *Usecase) AddTeam(ctx context.Context, team *domain.Team) (domain.Team, error) {
 	const op = "teams.Usecase.AddTeam"
 
+	var created domain.Team
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		created, err = u.addTeam(ctx, team)
+		return err
+	})
+	if err != nil {
+		return domain.Team{}, err
+	}
+
+	if _, err = u.Backfiller.BackfillPullRequests(ctx, team.TeamName); err != nil {
+		log.Printf("%s: backfill after adding team: %v\n", op, err)
+	}
+
+	return created, nil
+}
+
+func (u *Usecase) addTeam(ctx context.Context, team *domain.Team) (domain.Team, error) {
+	const op = "teams.Usecase.AddTeam"
+
 	fail := func(code domain.ErrorCode, message string, err error) (domain.Team, error) {
 		log.Printf("%s: %v\n", op, err)
 		return domain.Team{}, domain.NewError(code, message, err)
 	}
 
+	if team.RequiredReviewers == 0 {
+		team.RequiredReviewers = u.DefaultRequiredReviewers
+	}
+
+	if team.FallbackTeams == nil {
+		team.FallbackTeams = []string{}
+	}
+
+	if err := u.validateFallbackTeams(ctx, team.TeamName, team.FallbackTeams); err != nil {
+		return domain.Team{}, err
+	}
+
 	err := u.TeamsRepository.CreateTeam(ctx, team)
 	if err != nil {
 		return fail(domain.TEAM_EXISTS, fmt.Sprintf("%s already exists", team.TeamName), err)
@@ -70,3 +125,102 @@ func (u *Usecase) AddTeam(ctx context.Context, team *domain.Team) (domain.Team,
 
 	return *team, nil
 }
+
+// UpdateTeam changes the team settings. A new required_reviewers changes which
+// OPEN pull requests of the team are understaffed, so the team is backfilled
+// afterwards.
+func (u *Usecase) UpdateTeam(ctx context.Context, teamName string, settings domain.TeamSettings, expectedVersion *int64) (domain.Team, error) {
+	const op = "teams.Usecase.UpdateTeam"
+
+	var updated domain.Team
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		updated, err = u.updateTeam(ctx, teamName, settings, expectedVersion)
+		return err
+	})
+	if err != nil {
+		return domain.Team{}, err
+	}
+
+	if settings.RequiredReviewers != nil {
+		if _, err = u.Backfiller.BackfillPullRequests(ctx, teamName); err != nil {
+			log.Printf("%s: backfill after update: %v\n", op, err)
+		}
+	}
+
+	return updated, nil
+}
+
+func (u *Usecase) updateTeam(ctx context.Context, teamName string, settings domain.TeamSettings, expectedVersion *int64) (domain.Team, error) {
+	const op = "teams.Usecase.UpdateTeam"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.Team, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.Team{}, domain.NewError(code, message, err)
+	}
+
+	if err := u.TeamsRepository.LockTeam(ctx, teamName); err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	team, err := u.TeamsRepository.FetchTeamByName(ctx, teamName)
+	if err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	if !domain.VersionMatches(expectedVersion, team.Version) {
+		return fail(domain.PRECONDITION_FAILED, "team was modified", nil)
+	}
+
+	if settings.RequiredReviewers != nil {
+		team.RequiredReviewers = *settings.RequiredReviewers
+		if team.RequiredReviewers == 0 {
+			team.RequiredReviewers = u.DefaultRequiredReviewers
+		}
+	}
+
+	if settings.MaxOpenReviews.Set {
+		team.MaxOpenReviews = settings.MaxOpenReviews.Value
+	}
+
+	if settings.RequiredReviewers != nil || settings.MaxOpenReviews.Set {
+		if err = u.TeamsRepository.UpdateTeam(ctx, &team); err != nil {
+			return fail(domain.NOT_FOUND, "resource not found", err)
+		}
+	}
+
+	if settings.FallbackTeams != nil {
+		if err = u.validateFallbackTeams(ctx, teamName, *settings.FallbackTeams); err != nil {
+			return domain.Team{}, err
+		}
+
+		if err = u.TeamsRepository.SetFallbackTeams(ctx, teamName, *settings.FallbackTeams); err != nil {
+			return fail(domain.INTERNAL, "internal server error", err)
+		}
+	}
+
+	return u.GetTeam(ctx, teamName)
+}
+
+func (u *Usecase) validateFallbackTeams(ctx context.Context, teamName string, fallbackTeams []string) error {
+	const op = "teams.Usecase.validateFallbackTeams"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	seen := make(map[string]bool, len(fallbackTeams))
+	for _, fallbackTeam := range fallbackTeams {
+		if fallbackTeam == teamName || seen[fallbackTeam] {
+			return fail(domain.BAD_REQUEST, fmt.Sprintf("invalid fallback team %s", fallbackTeam), nil)
+		}
+		seen[fallbackTeam] = true
+
+		if _, err := u.TeamsRepository.FetchTeamByName(ctx, fallbackTeam); err != nil {
+			return fail(domain.NOT_FOUND, fmt.Sprintf("fallback team %s not found", fallbackTeam), err)
+		}
+	}
+
+	return nil
+}
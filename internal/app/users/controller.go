@@ -9,7 +9,13 @@ import (
 
 type Controller interface {
 	SetIsActive(w http.ResponseWriter, r *http.Request)
+	SetMaxOpenReviews(w http.ResponseWriter, r *http.Request)
 	GetReview(w http.ResponseWriter, r *http.Request)
 
+	AddAbsence(w http.ResponseWriter, r *http.Request)
+	UpdateAbsence(w http.ResponseWriter, r *http.Request)
+	DeleteAbsence(w http.ResponseWriter, r *http.Request)
+	GetAbsences(w http.ResponseWriter, r *http.Request)
+
 	SetupRoutes(r chi.Router, cfg *config.Config)
 }
@@ -4,6 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/leoscrowi/pr-assignment-service/domain"
@@ -20,6 +24,159 @@ func NewUsersController(usecase users.Usecase) *UsersController {
 	return &UsersController{usecase: usecase}
 }
 
+func (c *UsersController) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("user_id is required")))
+		return
+	}
+
+	user, err := c.usecase.GetUser(r.Context(), userID)
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.UserResponse{User: user}
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func (c *UsersController) ListUsers(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseUserFilter(r.URL.Query())
+	if err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	page, err := c.usecase.ListUsers(r.Context(), filter)
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.ListUsersResponse{
+		Users:      page.Users,
+		NextCursor: page.NextCursor,
+	}
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func parseUserFilter(query url.Values) (domain.UserFilter, error) {
+	filter := domain.UserFilter{
+		TeamName:   query.Get("team_name"),
+		NamePrefix: query.Get("name_prefix"),
+		Limit:      domain.DefaultUserPageSize,
+	}
+
+	if raw := query.Get("is_active"); raw != "" {
+		isActive, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, fmt.Errorf("is_active: %w", err)
+		}
+		filter.IsActive = &isActive
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > domain.MaxUserPageSize {
+			return filter, fmt.Errorf("limit must be between 1 and %d", domain.MaxUserPageSize)
+		}
+		filter.Limit = limit
+	}
+
+	if raw := query.Get("cursor"); raw != "" {
+		cursor, err := domain.DecodeUserCursor(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid cursor: %w", err)
+		}
+		filter.After = &cursor
+	}
+
+	return filter, nil
+}
+
+func (c *UsersController) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req dtos.CreateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	if req.UserID == "" || req.Username == "" || req.TeamName == "" || !domain.ValidMaxOpenReviews(req.MaxOpenReviews) {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
+		return
+	}
+
+	user := domain.User{
+		UserID:         req.UserID,
+		Username:       req.Username,
+		TeamName:       req.TeamName,
+		IsActive:       true,
+		MaxOpenReviews: req.MaxOpenReviews,
+	}
+	if req.IsActive != nil {
+		user.IsActive = *req.IsActive
+	}
+
+	created, err := c.usecase.CreateUser(r.Context(), &user)
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.UserResponse{User: created}
+	utils.WriteHeader(w, http.StatusCreated, &resp)
+}
+
+func (c *UsersController) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	var req dtos.UpdateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	if req.UserID == "" || req.Username == "" {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
+		return
+	}
+
+	user, err := c.usecase.UpdateUser(r.Context(), req.UserID, req.Username)
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.UserResponse{User: user}
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func (c *UsersController) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "user_id")
+
+	if userID == "" {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
+		return
+	}
+
+	force := false
+	if raw := r.URL.Query().Get("force"); raw != "" {
+		var err error
+		if force, err = strconv.ParseBool(raw); err != nil {
+			domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+			return
+		}
+	}
+
+	result, err := c.usecase.DeleteUser(r.Context(), userID, force)
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.DeleteUserResponse{Result: result}
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
 func (c *UsersController) SetIsActive(w http.ResponseWriter, r *http.Request) {
 	var req dtos.SetIsActiveRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -32,13 +189,84 @@ func (c *UsersController) SetIsActive(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := c.usecase.SetIsActive(r.Context(), req.UserID, req.IsActive)
+	reassign := false
+	if raw := r.URL.Query().Get("reassign"); raw != "" {
+		var err error
+		if reassign, err = strconv.ParseBool(raw); err != nil {
+			domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+			return
+		}
+	}
+
+	user, reassignments, err := c.usecase.SetIsActive(r.Context(), req.UserID, req.IsActive, reassign)
 	if err != nil {
 		domain.WriteError(w, domain.ConvertToErrorResponse(err))
 		return
 	}
 
 	var resp = dtos.SetIsActiveResponse{
+		User:          user,
+		Reassignments: reassignments,
+	}
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func (c *UsersController) MoveTeam(w http.ResponseWriter, r *http.Request) {
+	var req dtos.MoveTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	if req.UserID == "" || req.TeamName == "" {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
+		return
+	}
+
+	reassign := false
+	if raw := r.URL.Query().Get("reassign"); raw != "" {
+		var err error
+		if reassign, err = strconv.ParseBool(raw); err != nil {
+			domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+			return
+		}
+	}
+
+	user, move, reassignments, err := c.usecase.MoveTeam(r.Context(), req.UserID, req.TeamName, reassign)
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.MoveTeamResponse{
+		User:          user,
+		Reassignments: reassignments,
+	}
+	if move.MoveID != 0 {
+		resp.Move = &move
+	}
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func (c *UsersController) SetMaxOpenReviews(w http.ResponseWriter, r *http.Request) {
+	var req dtos.SetMaxOpenReviewsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	if req.UserID == "" || !domain.ValidMaxOpenReviews(req.MaxOpenReviews) {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
+		return
+	}
+
+	user, err := c.usecase.SetMaxOpenReviews(r.Context(), req.UserID, req.MaxOpenReviews)
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.SetMaxOpenReviewsResponse{
 		User: user,
 	}
 	utils.WriteHeader(w, http.StatusOK, &resp)
@@ -52,11 +280,185 @@ func (c *UsersController) GetReview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	prs, err := c.usecase.GetReview(r.Context(), userID)
+	query := r.URL.Query()
+	filter, err := parseReviewFilter(query)
+	if err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+	filter.ReviewerID = userID
+
+	details := false
+	if raw := query.Get("details"); raw != "" {
+		if details, err = strconv.ParseBool(raw); err != nil {
+			domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+			return
+		}
+	}
+
+	page, err := c.usecase.GetReview(r.Context(), filter)
 	if err != nil {
 		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	if details {
+		var resp = dtos.GetReviewDetailsResponse{UserID: userID, PullRequests: page.PullRequests, NextCursor: page.NextCursor}
+		utils.WriteHeader(w, http.StatusOK, &resp)
+		return
+	}
+
+	prs := make([]domain.PullRequestShort, 0, len(page.PullRequests))
+	for _, pr := range page.PullRequests {
+		prs = append(prs, domain.PullRequestShort{
+			PullRequestID:   pr.PullRequestID,
+			PullRequestName: pr.PullRequestName,
+			AuthorID:        pr.AuthorID,
+			Status:          pr.Status,
+		})
+	}
+
+	var resp = dtos.GetReviewResponse{UserID: userID, PullRequests: prs, NextCursor: page.NextCursor}
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+// parseReviewFilter reads a comma separated status list (OPEN by default, "all"
+// for any status), order, limit and cursor.
+func parseReviewFilter(query url.Values) (domain.ReviewFilter, error) {
+	filter := domain.ReviewFilter{
+		Statuses: []domain.Status{domain.OPEN},
+		Limit:    domain.DefaultPullRequestPageSize,
+	}
+
+	switch raw := query.Get("status"); raw {
+	case "":
+	case "all":
+		filter.Statuses = nil
+	default:
+		filter.Statuses = nil
+		for _, part := range strings.Split(raw, ",") {
+			switch status := domain.Status(strings.TrimSpace(part)); status {
+			case domain.DRAFT, domain.OPEN, domain.MERGED, domain.CLOSED:
+				filter.Statuses = append(filter.Statuses, status)
+			default:
+				return filter, fmt.Errorf("unknown status %q", part)
+			}
+		}
+	}
+
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		filter.Desc = true
+	default:
+		return filter, fmt.Errorf("order must be asc or desc")
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > domain.MaxPullRequestPageSize {
+			return filter, fmt.Errorf("limit must be between 1 and %d", domain.MaxPullRequestPageSize)
+		}
+		filter.Limit = limit
+	}
+
+	if raw := query.Get("cursor"); raw != "" {
+		cursor, err := domain.DecodePullRequestCursor(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid cursor: %w", err)
+		}
+		if _, err = time.Parse(time.RFC3339Nano, cursor.Value); err != nil {
+			return filter, fmt.Errorf("invalid cursor: %w", err)
+		}
+		filter.After = &cursor
+	}
+
+	return filter, nil
+}
+
+func (c *UsersController) AddAbsence(w http.ResponseWriter, r *http.Request) {
+	var req dtos.AddAbsenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	if req.UserID == "" || req.From.IsZero() || req.To.IsZero() {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
+		return
+	}
+
+	absence, err := c.usecase.AddAbsence(r.Context(), &domain.Absence{
+		UserID: req.UserID,
+		From:   req.From,
+		To:     req.To,
+		Reason: req.Reason,
+	})
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.AbsenceResponse{Absence: absence}
+	utils.WriteHeader(w, http.StatusCreated, &resp)
+}
+
+func (c *UsersController) UpdateAbsence(w http.ResponseWriter, r *http.Request) {
+	var req dtos.UpdateAbsenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	if req.AbsenceID == 0 || req.From.IsZero() || req.To.IsZero() {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
+		return
+	}
+
+	absence, err := c.usecase.UpdateAbsence(r.Context(), &domain.Absence{
+		AbsenceID: req.AbsenceID,
+		From:      req.From,
+		To:        req.To,
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	var resp = dtos.AbsenceResponse{Absence: absence}
+	utils.WriteHeader(w, http.StatusOK, &resp)
+}
+
+func (c *UsersController) DeleteAbsence(w http.ResponseWriter, r *http.Request) {
+	absenceID, err := strconv.ParseInt(chi.URLParam(r, "absence_id"), 10, 64)
+	if err != nil {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+		return
+	}
+
+	if err = c.usecase.DeleteAbsence(r.Context(), absenceID); err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *UsersController) GetAbsences(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "user_id")
+
+	if userID == "" {
+		domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", fmt.Errorf("wrong json format")))
+		return
+	}
+
+	absences, err := c.usecase.GetAbsences(r.Context(), userID)
+	if err != nil {
+		domain.WriteError(w, domain.ConvertToErrorResponse(err))
+		return
 	}
 
-	var resp = dtos.GetReviewResponse{UserID: userID, PullRequests: prs}
+	var resp = dtos.GetAbsencesResponse{UserID: userID, Absences: absences}
 	utils.WriteHeader(w, http.StatusOK, &resp)
 }
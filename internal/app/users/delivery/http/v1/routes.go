@@ -8,7 +8,20 @@ import (
 
 func (c *UsersController) SetupRoutes(r chi.Router, cfg *config.Config) {
 	r.Route("/users", func(r chi.Router) {
+		r.With(middleware.AuthMiddleware(cfg)).Get("/get", c.GetUser)
+		r.With(middleware.AuthMiddleware(cfg)).Get("/list", c.ListUsers)
+		r.With(middleware.AdminMiddleware(cfg)).Post("/create", c.CreateUser)
+		r.With(middleware.AdminMiddleware(cfg)).Patch("/update", c.UpdateUser)
+		r.With(middleware.AdminMiddleware(cfg)).Delete("/delete/{user_id}", c.DeleteUser)
+
 		r.With(middleware.AuthMiddleware(cfg)).Get("/getReview/{user_id}", c.GetReview)
 		r.With(middleware.AdminMiddleware(cfg)).Patch("/setIsActive", c.SetIsActive)
+		r.With(middleware.AdminMiddleware(cfg)).Patch("/setMaxOpenReviews", c.SetMaxOpenReviews)
+		r.With(middleware.AdminMiddleware(cfg)).Patch("/moveTeam", c.MoveTeam)
+
+		r.With(middleware.AuthMiddleware(cfg)).Get("/getAbsences/{user_id}", c.GetAbsences)
+		r.With(middleware.AdminMiddleware(cfg)).Post("/addAbsence", c.AddAbsence)
+		r.With(middleware.AdminMiddleware(cfg)).Patch("/updateAbsence", c.UpdateAbsence)
+		r.With(middleware.AdminMiddleware(cfg)).Delete("/deleteAbsence/{absence_id}", c.DeleteAbsence)
 	})
 }
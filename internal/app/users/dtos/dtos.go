@@ -1,6 +1,36 @@
 package dtos
 
-import "github.com/leoscrowi/pr-assignment-service/domain"
+import (
+	"time"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+)
+
+type UserResponse struct {
+	User domain.User `json:"user"`
+}
+
+type ListUsersResponse struct {
+	Users      []domain.User `json:"users"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+type CreateUserRequest struct {
+	UserID         string `json:"user_id"`
+	Username       string `json:"username"`
+	TeamName       string `json:"team_name"`
+	IsActive       *bool  `json:"is_active"`
+	MaxOpenReviews *int   `json:"max_open_reviews"`
+}
+
+type UpdateUserRequest struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+}
+
+type DeleteUserResponse struct {
+	Result domain.UserDeletion `json:"result"`
+}
 
 type SetIsActiveRequest struct {
 	UserID   string `json:"user_id"`
@@ -8,6 +38,27 @@ type SetIsActiveRequest struct {
 }
 
 type SetIsActiveResponse struct {
+	User          domain.User                 `json:"user"`
+	Reassignments []domain.ReassignmentResult `json:"reassignments,omitempty"`
+}
+
+type MoveTeamRequest struct {
+	UserID   string `json:"user_id"`
+	TeamName string `json:"team_name"`
+}
+
+type MoveTeamResponse struct {
+	User          domain.User                 `json:"user"`
+	Move          *domain.TeamMoveRecord      `json:"move,omitempty"`
+	Reassignments []domain.ReassignmentResult `json:"reassignments,omitempty"`
+}
+
+type SetMaxOpenReviewsRequest struct {
+	UserID         string `json:"user_id"`
+	MaxOpenReviews *int   `json:"max_open_reviews"`
+}
+
+type SetMaxOpenReviewsResponse struct {
 	User domain.User `json:"user"`
 }
 
@@ -18,4 +69,36 @@ type GetReviewRequest struct {
 type GetReviewResponse struct {
 	UserID       string                    `json:"user_id"`
 	PullRequests []domain.PullRequestShort `json:"pull_requests"`
+	NextCursor   string                    `json:"next_cursor,omitempty"`
+}
+
+// GetReviewDetailsResponse is returned for ?details=true with full pull requests,
+// including all their reviewers and created_at.
+type GetReviewDetailsResponse struct {
+	UserID       string               `json:"user_id"`
+	PullRequests []domain.PullRequest `json:"pull_requests"`
+	NextCursor   string               `json:"next_cursor,omitempty"`
+}
+
+type AddAbsenceRequest struct {
+	UserID string    `json:"user_id"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Reason string    `json:"reason"`
+}
+
+type UpdateAbsenceRequest struct {
+	AbsenceID int64     `json:"absence_id"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Reason    string    `json:"reason"`
+}
+
+type AbsenceResponse struct {
+	Absence domain.Absence `json:"absence"`
+}
+
+type GetAbsencesResponse struct {
+	UserID   string           `json:"user_id"`
+	Absences []domain.Absence `json:"absences"`
 }
@@ -2,15 +2,31 @@ package users
 
 import (
 	"context"
+	"time"
 
 	"github.com/leoscrowi/pr-assignment-service/domain"
 )
 
 type Repository interface {
 	SetIsActive(ctx context.Context, userID string, isActive bool) error
+	SetTeamMembersActive(ctx context.Context, teamName string, userIDs []string, isActive bool) ([]string, error)
+	MoveUser(ctx context.Context, userID, teamName string) (domain.TeamMoveRecord, error)
+	SetUsersTeam(ctx context.Context, userIDs []string, teamName *string) error
+	SetMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews *int) error
 	CreateOrUpdateUser(ctx context.Context, user *domain.User) (string, error)
+	CreateUser(ctx context.Context, user *domain.User) error
+	SetUsername(ctx context.Context, userID, username string) error
+	DeleteUser(ctx context.Context, userID string) error
 	FetchByID(ctx context.Context, userID string) (domain.User, error)
+	ListUsers(ctx context.Context, filter domain.UserFilter) ([]domain.User, error)
 	FetchByTeamName(ctx context.Context, teamName string) ([]domain.TeamMember, error)
 
 	GetActiveUsersIDByTeam(ctx context.Context, teamName string) ([]string, error)
+	GetCandidatesByTeam(ctx context.Context, teamName string) ([]domain.Candidate, error)
+
+	CreateAbsence(ctx context.Context, absence *domain.Absence) error
+	UpdateAbsence(ctx context.Context, absence *domain.Absence) error
+	DeleteAbsence(ctx context.Context, absenceID int64) error
+	FetchAbsences(ctx context.Context, userID string, since time.Time) ([]domain.Absence, error)
+	FetchAbsencesByTeam(ctx context.Context, teamName string, since time.Time) ([]domain.Absence, error)
 }
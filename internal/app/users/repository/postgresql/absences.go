@@ -0,0 +1,186 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/transaction"
+)
+
+const absencesTableName = "user_absences"
+
+func (r *Repository) CreateAbsence(ctx context.Context, absence *domain.Absence) error {
+	const op = "users.Repository.CreateAbsence"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Insert(absencesTableName).
+		Columns("user_id", "starts_at", "ends_at", "reason").
+		Values(absence.UserID, absence.From, absence.To, absence.Reason).
+		Suffix("RETURNING absence_id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.GetContext(ctx, &absence.AbsenceID, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) UpdateAbsence(ctx context.Context, absence *domain.Absence) error {
+	const op = "users.Repository.UpdateAbsence"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Update(absencesTableName).
+		Set("starts_at", absence.From).
+		Set("ends_at", absence.To).
+		Set("reason", absence.Reason).
+		Where(sq.Eq{"absence_id": absence.AbsenceID}).
+		Suffix("RETURNING user_id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.GetContext(ctx, &absence.UserID, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fail(domain.NOT_FOUND, "resource not found", err)
+		}
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteAbsence(ctx context.Context, absenceID int64) error {
+	const op = "users.Repository.DeleteAbsence"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Delete(absencesTableName).
+		Where(sq.Eq{"absence_id": absenceID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	if affected == 0 {
+		return fail(domain.NOT_FOUND, "resource not found", nil)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
+}
+
+// FetchAbsences returns absences of the user which have not ended before since.
+func (r *Repository) FetchAbsences(ctx context.Context, userID string, since time.Time) ([]domain.Absence, error) {
+	return r.fetchAbsences(ctx, "users.Repository.FetchAbsences", sq.Eq{"a.user_id": userID}, since)
+}
+
+// FetchAbsencesByTeam returns absences of the team members which have not ended before since.
+func (r *Repository) FetchAbsencesByTeam(ctx context.Context, teamName string, since time.Time) ([]domain.Absence, error) {
+	return r.fetchAbsences(ctx, "users.Repository.FetchAbsencesByTeam", sq.Eq{"u.team_name": teamName}, since)
+}
+
+func (r *Repository) fetchAbsences(ctx context.Context, op string, filter sq.Sqlizer, since time.Time) ([]domain.Absence, error) {
+	fail := func(code domain.ErrorCode, message string, err error) ([]domain.Absence, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Select("a.absence_id", "a.user_id", "a.starts_at", "a.ends_at", "a.reason").
+		From(absencesTableName+" a").
+		Join(tableName+" u ON u.user_id = a.user_id").
+		Where(filter).
+		Where(sq.Gt{"a.ends_at": since}).
+		OrderBy("a.starts_at", "a.absence_id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	result := []domain.Absence{}
+	if err = tx.SelectContext(ctx, &result, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,66 @@
+package postgresql
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/transaction"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ListUsers returns up to filter.Limit users matching the filter ordered by
+// user_id, starting right after filter.After.
+func (r *Repository) ListUsers(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	const op = "users.Repository.ListUsers"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]domain.User, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	builder := sq.Select("user_id", "username", "COALESCE(team_name, '') as team_name", "is_active", "max_open_reviews").
+		From(tableName).
+		Where(sq.Eq{"deleted_at": nil}).
+		OrderBy("user_id")
+
+	if filter.After != nil {
+		builder = builder.Where(sq.Gt{"user_id": filter.After.ID})
+	}
+	if filter.TeamName != "" {
+		builder = builder.Where(sq.Eq{"team_name": filter.TeamName})
+	}
+	if filter.IsActive != nil {
+		builder = builder.Where(sq.Eq{"is_active": *filter.IsActive})
+	}
+	if filter.NamePrefix != "" {
+		builder = builder.Where(sq.ILike{"username": likeEscaper.Replace(filter.NamePrefix) + "%"})
+	}
+
+	query, args, err := builder.Limit(uint64(filter.Limit)).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	result := []domain.User{}
+	if err = tx.SelectContext(ctx, &result, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return result, nil
+}
@@ -8,11 +8,15 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/transaction"
 
 	sq "github.com/Masterminds/squirrel"
 )
 
-const tableName = "users"
+const (
+	tableName      = "users"
+	movesTableName = "team_moves"
+)
 
 type Repository struct {
 	db *sqlx.DB
@@ -30,17 +34,17 @@ func (r *Repository) SetIsActive(ctx context.Context, userID string, isActive bo
 		return domain.NewError(code, message, err)
 	}
 
-	tx, err := r.db.BeginTxx(ctx, nil)
+	tx, err := transaction.Begin(ctx, r.db)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	defer func(tx *sqlx.Tx) {
+	defer func(tx *transaction.Tx) {
 		_ = tx.Rollback()
 	}(tx)
 
 	query, args, err := sq.Update(tableName).
 		Set("is_active", isActive).
-		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Eq{"user_id": userID, "deleted_at": nil}).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 
@@ -48,11 +52,19 @@ func (r *Repository) SetIsActive(ctx context.Context, userID string, isActive bo
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
-	_, err = tx.ExecContext(ctx, query, args...)
+	res, err := tx.ExecContext(ctx, query, args...)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
 
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	if affected == 0 {
+		return fail(domain.NOT_FOUND, "resource not found", nil)
+	}
+
 	if err = tx.Commit(); err != nil {
 		return fail(domain.INTERNAL, "", err)
 	}
@@ -60,6 +72,154 @@ func (r *Repository) SetIsActive(ctx context.Context, userID string, isActive bo
 	return nil
 }
 
+// SetTeamMembersActive updates the given members of the team, or all of them if
+// userIDs is empty, and returns the ids of the updated users.
+func (r *Repository) SetTeamMembersActive(ctx context.Context, teamName string, userIDs []string, isActive bool) ([]string, error) {
+	const op = "users.Repository.SetTeamMembersActive"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]string, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	builder := sq.Update(tableName).
+		Set("is_active", isActive).
+		Where(sq.Eq{"team_name": teamName}).
+		Suffix("RETURNING user_id").
+		PlaceholderFormat(sq.Dollar)
+	if len(userIDs) > 0 {
+		builder = builder.Where(sq.Eq{"user_id": userIDs})
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	updated := []string{}
+	if err = tx.SelectContext(ctx, &updated, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return updated, nil
+}
+
+// SetUsersTeam moves the users to the team, a nil teamName leaves them without one.
+func (r *Repository) SetUsersTeam(ctx context.Context, userIDs []string, teamName *string) error {
+	const op = "users.Repository.SetUsersTeam"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Update(tableName).
+		Set("team_name", teamName).
+		Where(sq.Eq{"user_id": userIDs}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
+}
+
+// MoveUser moves the user to the team and returns the move, which the
+// trg_users_team_move trigger records in team_moves like any other team change.
+func (r *Repository) MoveUser(ctx context.Context, userID, teamName string) (domain.TeamMoveRecord, error) {
+	const op = "users.Repository.MoveUser"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.TeamMoveRecord, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.TeamMoveRecord{}, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Update(tableName).
+		Set("team_name", teamName).
+		Where(sq.Eq{"user_id": userID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	if affected == 0 {
+		return fail(domain.NOT_FOUND, "resource not found", nil)
+	}
+
+	query, args, err = sq.Select("move_id", "user_id", "COALESCE(from_team, '') as from_team", "COALESCE(to_team, '') as to_team", "moved_at").
+		From(movesTableName).
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("move_id DESC").
+		Limit(1).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	var move domain.TeamMoveRecord
+	if err = tx.GetContext(ctx, &move, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return move, nil
+}
+
 func (r *Repository) CreateOrUpdateUser(ctx context.Context, user *domain.User) (string, error) {
 	const op = "users.Repository.CreateOrUpdateUser"
 
@@ -68,11 +228,11 @@ func (r *Repository) CreateOrUpdateUser(ctx context.Context, user *domain.User)
 		return "", domain.NewError(code, message, err)
 	}
 
-	tx, err := r.db.BeginTxx(ctx, nil)
+	tx, err := transaction.Begin(ctx, r.db)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	defer func(tx *sqlx.Tx) {
+	defer func(tx *transaction.Tx) {
 		_ = tx.Rollback()
 	}(tx)
 
@@ -82,7 +242,8 @@ func (r *Repository) CreateOrUpdateUser(ctx context.Context, user *domain.User)
 		Suffix("ON CONFLICT (user_id) DO UPDATE SET " +
 			"username = EXCLUDED.username, " +
 			"team_name = EXCLUDED.team_name, " +
-			"is_active = EXCLUDED.is_active").
+			"is_active = EXCLUDED.is_active, " +
+			"deleted_at = NULL").
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 
@@ -110,11 +271,11 @@ func (r *Repository) FetchByTeamName(ctx context.Context, teamName string) ([]do
 		return nil, domain.NewError(code, message, err)
 	}
 
-	tx, err := r.db.BeginTxx(ctx, nil)
+	tx, err := transaction.Begin(ctx, r.db)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	defer func(tx *sqlx.Tx) {
+	defer func(tx *transaction.Tx) {
 		_ = tx.Rollback()
 	}(tx)
 
@@ -168,15 +329,15 @@ func (r *Repository) FetchByID(ctx context.Context, userID string) (domain.User,
 		return domain.User{}, domain.NewError(code, message, err)
 	}
 
-	tx, err := r.db.BeginTxx(ctx, nil)
+	tx, err := transaction.Begin(ctx, r.db)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	defer func(tx *sqlx.Tx) {
+	defer func(tx *transaction.Tx) {
 		_ = tx.Rollback()
 	}(tx)
 
-	query, args, err := sq.Select("user_id", "username", "team_name", "is_active").From(tableName).Where(sq.Eq{"user_id": userID}).PlaceholderFormat(sq.Dollar).ToSql()
+	query, args, err := sq.Select("user_id", "username", "COALESCE(team_name, '') as team_name", "is_active", "max_open_reviews").From(tableName).Where(sq.Eq{"user_id": userID, "deleted_at": nil}).PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
@@ -204,11 +365,11 @@ func (r *Repository) GetActiveUsersIDByTeam(ctx context.Context, teamName string
 		return nil, domain.NewError(code, message, err)
 	}
 
-	tx, err := r.db.BeginTxx(ctx, nil)
+	tx, err := transaction.Begin(ctx, r.db)
 	if err != nil {
 		return fail(domain.INTERNAL, "internal server error", err)
 	}
-	defer func(tx *sqlx.Tx) {
+	defer func(tx *transaction.Tx) {
 		_ = tx.Rollback()
 	}(tx)
 
@@ -246,3 +407,246 @@ func (r *Repository) GetActiveUsersIDByTeam(ctx context.Context, teamName string
 
 	return result, nil
 }
+
+// GetCandidatesByTeam returns all team members with what is needed to decide whether
+// they can review: activity, current absence, number of OPEN reviews and capacity.
+// Least loaded members go first.
+func (r *Repository) GetCandidatesByTeam(ctx context.Context, teamName string) ([]domain.Candidate, error) {
+	const op = "users.Repository.GetCandidatesByTeam"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]domain.Candidate, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Select(
+		"u.user_id",
+		"u.team_name",
+		"u.is_active",
+		"EXISTS (SELECT 1 FROM "+absencesTableName+" a WHERE a.user_id = u.user_id AND now() >= a.starts_at AND now() < a.ends_at) AS absent",
+		"COUNT(pr.pull_request_id) AS open_reviews",
+		"COALESCE(u.max_open_reviews, t.max_open_reviews, 0) AS capacity",
+	).
+		From(tableName+" u").
+		Join("teams t ON t.team_name = u.team_name").
+		LeftJoin("pull_request_reviewers prr ON prr.reviewer_id = u.user_id").
+		LeftJoin("pull_requests pr ON pr.pull_request_id = prr.pull_request_id AND pr.status = ?", domain.OPEN).
+		Where(sq.Eq{"u.team_name": teamName}).
+		GroupBy("u.user_id", "t.team_name").
+		OrderBy("open_reviews", "u.user_id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	var result []domain.Candidate
+	if err = tx.SelectContext(ctx, &result, query, args...); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return result, nil
+}
+
+func (r *Repository) SetMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews *int) error {
+	const op = "users.Repository.SetMaxOpenReviews"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Update(tableName).
+		Set("max_open_reviews", maxOpenReviews).
+		Where(sq.Eq{"user_id": userID, "deleted_at": nil}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	if affected == 0 {
+		return fail(domain.NOT_FOUND, "resource not found", nil)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) CreateUser(ctx context.Context, user *domain.User) error {
+	const op = "users.Repository.CreateUser"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Insert(tableName).
+		Columns("user_id", "username", "team_name", "is_active", "max_open_reviews").
+		Values(user.UserID, user.Username, user.TeamName, user.IsActive, user.MaxOpenReviews).
+		Suffix("ON CONFLICT (user_id) DO UPDATE SET " +
+			"username = EXCLUDED.username, " +
+			"team_name = EXCLUDED.team_name, " +
+			"is_active = EXCLUDED.is_active, " +
+			"max_open_reviews = EXCLUDED.max_open_reviews, " +
+			"deleted_at = NULL " +
+			"WHERE " + tableName + ".deleted_at IS NOT NULL").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	if affected == 0 {
+		return fail(domain.USER_EXISTS, "user already exists", nil)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) SetUsername(ctx context.Context, userID, username string) error {
+	const op = "users.Repository.SetUsername"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Update(tableName).
+		Set("username", username).
+		Where(sq.Eq{"user_id": userID, "deleted_at": nil}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	if affected == 0 {
+		return fail(domain.NOT_FOUND, "resource not found", nil)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
+}
+
+// DeleteUser marks the user as deleted, deactivates them and takes them out of
+// their team. The row stays, so their pull requests and reviews are kept.
+func (r *Repository) DeleteUser(ctx context.Context, userID string) error {
+	const op = "users.Repository.DeleteUser"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	tx, err := transaction.Begin(ctx, r.db)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *transaction.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args, err := sq.Update(tableName).
+		Set("deleted_at", sq.Expr("now()")).
+		Set("is_active", false).
+		Set("team_name", nil).
+		Where(sq.Eq{"user_id": userID, "deleted_at": nil}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	if affected == 0 {
+		return fail(domain.NOT_FOUND, "resource not found", nil)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
+}
@@ -7,6 +7,19 @@ import (
 )
 
 type Usecase interface {
-	SetIsActive(ctx context.Context, userID string, isActive bool) (domain.User, error)
-	GetReview(ctx context.Context, userID string) ([]domain.PullRequestShort, error)
+	GetUser(ctx context.Context, userID string) (domain.User, error)
+	ListUsers(ctx context.Context, filter domain.UserFilter) (domain.UserPage, error)
+	CreateUser(ctx context.Context, user *domain.User) (domain.User, error)
+	UpdateUser(ctx context.Context, userID, username string) (domain.User, error)
+	DeleteUser(ctx context.Context, userID string, force bool) (domain.UserDeletion, error)
+
+	SetIsActive(ctx context.Context, userID string, isActive bool, reassignReviews bool) (domain.User, []domain.ReassignmentResult, error)
+	MoveTeam(ctx context.Context, userID, teamName string, reassignReviews bool) (domain.User, domain.TeamMoveRecord, []domain.ReassignmentResult, error)
+	SetMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews *int) (domain.User, error)
+	GetReview(ctx context.Context, filter domain.ReviewFilter) (domain.ReviewPage, error)
+
+	AddAbsence(ctx context.Context, absence *domain.Absence) (domain.Absence, error)
+	UpdateAbsence(ctx context.Context, absence *domain.Absence) (domain.Absence, error)
+	DeleteAbsence(ctx context.Context, absenceID int64) error
+	GetAbsences(ctx context.Context, userID string) ([]domain.Absence, error)
 }
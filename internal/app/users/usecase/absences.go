@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+)
+
+func (u *Usecase) AddAbsence(ctx context.Context, absence *domain.Absence) (domain.Absence, error) {
+	const op = "users.Usecase.AddAbsence"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.Absence, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.Absence{}, domain.NewError(code, message, err)
+	}
+
+	if !absence.To.After(absence.From) {
+		return fail(domain.BAD_REQUEST, "absence must end after it starts", nil)
+	}
+
+	if _, err := u.UsersRepository.FetchByID(ctx, absence.UserID); err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	if err := u.UsersRepository.CreateAbsence(ctx, absence); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return *absence, nil
+}
+
+func (u *Usecase) UpdateAbsence(ctx context.Context, absence *domain.Absence) (domain.Absence, error) {
+	const op = "users.Usecase.UpdateAbsence"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.Absence, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.Absence{}, domain.NewError(code, message, err)
+	}
+
+	if !absence.To.After(absence.From) {
+		return fail(domain.BAD_REQUEST, "absence must end after it starts", nil)
+	}
+
+	if err := u.UsersRepository.UpdateAbsence(ctx, absence); err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	return *absence, nil
+}
+
+func (u *Usecase) DeleteAbsence(ctx context.Context, absenceID int64) error {
+	const op = "users.Usecase.DeleteAbsence"
+
+	if err := u.UsersRepository.DeleteAbsence(ctx, absenceID); err != nil {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	return nil
+}
+
+// GetAbsences returns current and upcoming absences of the user.
+func (u *Usecase) GetAbsences(ctx context.Context, userID string) ([]domain.Absence, error) {
+	const op = "users.Usecase.GetAbsences"
+
+	fail := func(code domain.ErrorCode, message string, err error) ([]domain.Absence, error) {
+		log.Printf("%s: %v\n", op, err)
+		return nil, domain.NewError(code, message, err)
+	}
+
+	if _, err := u.UsersRepository.FetchByID(ctx, userID); err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	absences, err := u.UsersRepository.FetchAbsences(ctx, userID, time.Now())
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return absences, nil
+}
@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/app/pull_requests"
+)
+
+func (u *Usecase) GetUser(ctx context.Context, userID string) (domain.User, error) {
+	return u.UsersRepository.FetchByID(ctx, userID)
+}
+
+// ListUsers reads one page of the user directory. One extra row is requested
+// to find out whether the next page exists.
+func (u *Usecase) ListUsers(ctx context.Context, filter domain.UserFilter) (domain.UserPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = domain.DefaultUserPageSize
+	}
+	limit := filter.Limit
+	filter.Limit++
+
+	users, err := u.UsersRepository.ListUsers(ctx, filter)
+	if err != nil {
+		return domain.UserPage{}, err
+	}
+
+	page := domain.UserPage{Users: users}
+	if len(users) > limit {
+		page.Users = users[:limit]
+		page.NextCursor = domain.UserCursor{ID: page.Users[limit-1].UserID}.Encode()
+	}
+
+	return page, nil
+}
+
+// CreateUser adds a new member to an existing team. An active member may take
+// reviews right away, so the team is backfilled afterwards.
+func (u *Usecase) CreateUser(ctx context.Context, user *domain.User) (domain.User, error) {
+	const op = "users.Usecase.CreateUser"
+
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		return u.createUser(ctx, user)
+	})
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if user.IsActive {
+		if _, err = u.Backfiller.BackfillPullRequests(ctx, user.TeamName); err != nil {
+			log.Printf("%s: backfill after create: %v\n", op, err)
+		}
+	}
+
+	return *user, nil
+}
+
+func (u *Usecase) createUser(ctx context.Context, user *domain.User) error {
+	const op = "users.Usecase.CreateUser"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	if err := u.TeamsRepository.LockTeam(ctx, user.TeamName); err != nil {
+		return fail(domain.NOT_FOUND, "team not found", err)
+	}
+
+	_, err := u.UsersRepository.FetchByID(ctx, user.UserID)
+	switch {
+	case err == nil:
+		return fail(domain.USER_EXISTS, fmt.Sprintf("%s already exists", user.UserID), nil)
+	case !domain.HasCode(err, domain.NOT_FOUND):
+		return err
+	}
+
+	if err = u.UsersRepository.CreateUser(ctx, user); err != nil {
+		if domain.HasCode(err, domain.USER_EXISTS) {
+			return fail(domain.USER_EXISTS, fmt.Sprintf("%s already exists", user.UserID), err)
+		}
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
+}
+
+func (u *Usecase) UpdateUser(ctx context.Context, userID, username string) (domain.User, error) {
+	const op = "users.Usecase.UpdateUser"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.User, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.User{}, domain.NewError(code, message, err)
+	}
+
+	if err := u.UsersRepository.SetUsername(ctx, userID, username); err != nil {
+		if domain.HasCode(err, domain.NOT_FOUND) {
+			return fail(domain.NOT_FOUND, "resource not found", err)
+		}
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return u.UsersRepository.FetchByID(ctx, userID)
+}
+
+// DeleteUser soft-deletes the user: the user is deactivated and taken out of the
+// team, while merged pull requests and reviews stay in place. While the user
+// authors OPEN or DRAFT pull requests or reviews OPEN ones the deletion is
+// refused, unless force is set: then their pull requests are closed and their
+// reviews are reassigned.
+func (u *Usecase) DeleteUser(ctx context.Context, userID string, force bool) (domain.UserDeletion, error) {
+	var result domain.UserDeletion
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = u.deleteUser(ctx, userID, force)
+		return err
+	})
+	return result, err
+}
+
+func (u *Usecase) deleteUser(ctx context.Context, userID string, force bool) (domain.UserDeletion, error) {
+	const op = "users.Usecase.DeleteUser"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.UserDeletion, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.UserDeletion{}, domain.NewError(code, message, err)
+	}
+
+	user, err := u.UsersRepository.FetchByID(ctx, userID)
+	if err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	// The user's pull requests and team are locked before they are counted, so
+	// no review can be assigned to the user between the busy check and the
+	// deletion, see LockForChange.
+	scope := pull_requests.LockScope{UserIDs: []string{userID}, TeamNames: []string{user.TeamName}}
+	if err = u.Locker.LockForChange(ctx, scope); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	authored, err := u.PullRequestsRepository.FindActiveIDsByAuthors(ctx, []string{userID})
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	reviewed, err := u.PullRequestsRepository.FindOpenReviewIDs(ctx, []string{userID})
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if !force && (len(authored) > 0 || len(reviewed) > 0) {
+		return fail(domain.USER_IS_BUSY,
+			fmt.Sprintf("%s authors %d and reviews %d open pull requests", userID, len(authored), len(reviewed)), nil)
+	}
+
+	// The user is deactivated first, so the reassignment does not pick them again.
+	if err = u.UsersRepository.SetIsActive(ctx, userID, false); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	closed, err := u.AuthorReleaser.CloseAuthoredPullRequests(ctx, []string{userID})
+	if err != nil {
+		return fail(domain.INTERNAL, "failed to close pull requests", err)
+	}
+
+	reassignments, err := u.ReviewerReleaser.ReleaseReviewers(ctx, []string{userID})
+	if err != nil {
+		return fail(domain.INTERNAL, "failed to reassign reviews", err)
+	}
+
+	if err = u.UsersRepository.DeleteUser(ctx, userID); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return domain.UserDeletion{
+		UserID:             userID,
+		Reassignments:      reassignments,
+		ClosedPullRequests: closed,
+	}, nil
+}
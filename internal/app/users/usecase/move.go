@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"log"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/app/pull_requests"
+)
+
+// MoveTeam moves the user to another team and records the move. Their OPEN reviews
+// are kept unless reassignReviews is set, then the reviews the user is no longer
+// eligible for in the new team are reassigned in the same transaction.
+func (u *Usecase) MoveTeam(ctx context.Context, userID, teamName string, reassignReviews bool) (domain.User, domain.TeamMoveRecord, []domain.ReassignmentResult, error) {
+	const op = "users.Usecase.MoveTeam"
+
+	var (
+		user          domain.User
+		move          domain.TeamMoveRecord
+		reassignments []domain.ReassignmentResult
+	)
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		user, move, reassignments, err = u.moveTeam(ctx, userID, teamName, reassignReviews)
+		return err
+	})
+	if err != nil {
+		return domain.User{}, domain.TeamMoveRecord{}, nil, err
+	}
+
+	if move.MoveID != 0 {
+		if _, err = u.Backfiller.BackfillPullRequests(ctx, teamName); err != nil {
+			log.Printf("%s: backfill after move: %v\n", op, err)
+		}
+	}
+
+	return user, move, reassignments, nil
+}
+
+func (u *Usecase) moveTeam(ctx context.Context, userID, teamName string, reassignReviews bool) (domain.User, domain.TeamMoveRecord, []domain.ReassignmentResult, error) {
+	const op = "users.Usecase.MoveTeam"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.User, domain.TeamMoveRecord, []domain.ReassignmentResult, error) {
+		log.Printf("%s: %v\n", op, err)
+		return domain.User{}, domain.TeamMoveRecord{}, nil, domain.NewError(code, message, err)
+	}
+
+	user, err := u.UsersRepository.FetchByID(ctx, userID)
+	if err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	// The user's pull requests are locked before both teams, the move updates
+	// the old team as well, see LockForChange.
+	if err = u.Locker.LockForChange(ctx, pull_requests.LockScope{UserIDs: []string{userID}, TeamNames: []string{user.TeamName, teamName}}); err != nil {
+		if domain.HasCode(err, domain.NOT_FOUND) {
+			return fail(domain.NOT_FOUND, "team not found", err)
+		}
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	if user, err = u.UsersRepository.FetchByID(ctx, userID); err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	if user.TeamName == teamName {
+		return user, domain.TeamMoveRecord{}, nil, nil
+	}
+
+	move, err := u.UsersRepository.MoveUser(ctx, userID, teamName)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	user.TeamName = teamName
+
+	var reassignments []domain.ReassignmentResult
+	if reassignReviews {
+		reassignments, err = u.ReviewerReleaser.ReleaseForeignReviews(ctx, userID)
+		if err != nil {
+			return fail(domain.INTERNAL, "failed to reassign reviews", err)
+		}
+	}
+
+	return user, move, reassignments, nil
+}
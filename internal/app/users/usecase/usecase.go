@@ -3,27 +3,65 @@ package usecase
 import (
 	"context"
 	"log"
+	"time"
 
 	"github.com/leoscrowi/pr-assignment-service/domain"
 	"github.com/leoscrowi/pr-assignment-service/internal/app/pull_requests"
+	"github.com/leoscrowi/pr-assignment-service/internal/app/teams"
 	"github.com/leoscrowi/pr-assignment-service/internal/app/users"
+	"github.com/leoscrowi/pr-assignment-service/internal/transaction"
 )
 
 type Usecase struct {
 	UsersRepository        users.Repository
 	PullRequestsRepository pull_requests.Repository
+	TeamsRepository        teams.Repository
+	Backfiller             pull_requests.Backfiller
+	ReviewerReleaser       pull_requests.ReviewerReleaser
+	AuthorReleaser         pull_requests.AuthorReleaser
+	Locker                 pull_requests.Locker
+	TxManager              transaction.Manager
 }
 
-func NewUsecase(uRepository users.Repository, prRepository pull_requests.Repository) *Usecase {
-	return &Usecase{UsersRepository: uRepository, PullRequestsRepository: prRepository}
+func NewUsecase(uRepository users.Repository, prRepository pull_requests.Repository, tRepository teams.Repository, backfiller pull_requests.Backfiller, releaser pull_requests.ReviewerReleaser, authorReleaser pull_requests.AuthorReleaser, locker pull_requests.Locker, txManager transaction.Manager) *Usecase {
+	return &Usecase{UsersRepository: uRepository, PullRequestsRepository: prRepository, TeamsRepository: tRepository, Backfiller: backfiller, ReviewerReleaser: releaser, AuthorReleaser: authorReleaser, Locker: locker, TxManager: txManager}
 }
 
-func (u *Usecase) SetIsActive(ctx context.Context, userID string, isActive bool) (domain.User, error) {
+// SetIsActive updates the user activity. With reassignReviews set, a deactivated
+// user is also replaced on all their OPEN reviews in the same transaction.
+func (u *Usecase) SetIsActive(ctx context.Context, userID string, isActive bool, reassignReviews bool) (domain.User, []domain.ReassignmentResult, error) {
 	const op = "users.Usecase.SetIsActive"
 
-	fail := func(code domain.ErrorCode, message string, err error) (domain.User, error) {
+	var (
+		user          domain.User
+		reassignments []domain.ReassignmentResult
+	)
+	err := u.TxManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		user, reassignments, err = u.setIsActive(ctx, userID, isActive, reassignReviews)
+		return err
+	})
+	if err != nil {
+		return domain.User{}, nil, err
+	}
+
+	if isActive && !user.IsActive {
+		if _, err = u.Backfiller.BackfillPullRequests(ctx, user.TeamName); err != nil {
+			log.Printf("%s: backfill after activation: %v\n", op, err)
+		}
+	}
+
+	user.IsActive = isActive
+	return user, reassignments, nil
+}
+
+// setIsActive returns the user as it was before the update.
+func (u *Usecase) setIsActive(ctx context.Context, userID string, isActive bool, reassignReviews bool) (domain.User, []domain.ReassignmentResult, error) {
+	const op = "users.Usecase.SetIsActive"
+
+	fail := func(code domain.ErrorCode, message string, err error) (domain.User, []domain.ReassignmentResult, error) {
 		log.Printf("%s: %v\n", op, err)
-		return domain.User{}, domain.NewError(code, message, err)
+		return domain.User{}, nil, domain.NewError(code, message, err)
 	}
 
 	user, err := u.UsersRepository.FetchByID(ctx, userID)
@@ -31,35 +69,76 @@ func (u *Usecase) SetIsActive(ctx context.Context, userID string, isActive bool)
 		return fail(domain.NOT_FOUND, "resource not found", err)
 	}
 
+	// Updating the user updates the team, so with reviews to reassign the user's
+	// pull requests are locked before it, see LockForChange.
+	if !isActive && reassignReviews {
+		scope := pull_requests.LockScope{UserIDs: []string{userID}, TeamNames: []string{user.TeamName}}
+		if err = u.Locker.LockForChange(ctx, scope); err != nil {
+			return fail(domain.INTERNAL, "internal server error", err)
+		}
+	}
+
 	err = u.UsersRepository.SetIsActive(ctx, userID, isActive)
 	if err != nil {
 		return fail(domain.NOT_FOUND, "resource not found", err)
 	}
 
-	user.IsActive = isActive
-	return user, nil
+	var reassignments []domain.ReassignmentResult
+	if !isActive && reassignReviews {
+		reassignments, err = u.ReviewerReleaser.ReleaseReviewers(ctx, []string{userID})
+		if err != nil {
+			return fail(domain.INTERNAL, "failed to reassign reviews", err)
+		}
+	}
+
+	return user, reassignments, nil
 }
 
-func (u *Usecase) GetReview(ctx context.Context, userID string) ([]domain.PullRequestShort, error) {
-	const op = "users.Usecase.GetReview"
+func (u *Usecase) SetMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews *int) (domain.User, error) {
+	const op = "users.Usecase.SetMaxOpenReviews"
 
-	fail := func(code domain.ErrorCode, message string, err error) ([]domain.PullRequestShort, error) {
+	fail := func(code domain.ErrorCode, message string, err error) (domain.User, error) {
 		log.Printf("%s: %v\n", op, err)
-		return []domain.PullRequestShort{}, domain.NewError(code, message, err)
+		return domain.User{}, domain.NewError(code, message, err)
 	}
 
-	prs, err := u.PullRequestsRepository.FindPullRequestsIDByUserID(ctx, userID)
+	user, err := u.UsersRepository.FetchByID(ctx, userID)
 	if err != nil {
-		return fail(domain.INTERNAL, "internal server error", err)
+		return fail(domain.NOT_FOUND, "resource not found", err)
 	}
 
-	result := []domain.PullRequestShort{}
-	for _, prID := range prs {
-		pr, err := u.PullRequestsRepository.FetchShortByID(ctx, prID)
-		if err != nil {
-			return fail(domain.NOT_FOUND, "resource not found", err)
-		}
-		result = append(result, pr)
+	err = u.UsersRepository.SetMaxOpenReviews(ctx, userID, maxOpenReviews)
+	if err != nil {
+		return fail(domain.NOT_FOUND, "resource not found", err)
+	}
+
+	user.MaxOpenReviews = maxOpenReviews
+	return user, nil
+}
+
+// GetReview reads one page of pull requests the user reviews. One extra row is
+// requested to find out whether the next page exists.
+func (u *Usecase) GetReview(ctx context.Context, filter domain.ReviewFilter) (domain.ReviewPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = domain.DefaultPullRequestPageSize
+	}
+	limit := filter.Limit
+	filter.Limit++
+
+	prs, err := u.PullRequestsRepository.ListReviews(ctx, filter)
+	if err != nil {
+		return domain.ReviewPage{}, err
 	}
-	return result, nil
+
+	page := domain.ReviewPage{PullRequests: prs}
+	if len(prs) > limit {
+		page.PullRequests = prs[:limit]
+		last := page.PullRequests[limit-1]
+		page.NextCursor = domain.PullRequestCursor{
+			Value: last.CreatedAt.UTC().Format(time.RFC3339Nano),
+			ID:    last.PullRequestID,
+		}.Encode()
+	}
+
+	return page, nil
 }
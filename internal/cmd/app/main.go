@@ -55,7 +55,7 @@ func main() {
 		log.Printf("Failed to up migrations: %v", err)
 		return
 	}
-	s := server.NewServer(db)
+	s := server.NewServer(db, cfg)
 	s.SetupRoutes(cfg)
 
 	log.Println(http.ListenAndServe(":8080", s.Router))
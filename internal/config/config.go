@@ -1,10 +1,26 @@
 package config
 
-import "os"
+import (
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRequiredReviewers = 2
+	defaultIdempotencyTTL    = 24 * time.Hour
+	defaultIdempotencyLease  = time.Minute
+	defaultIdempotencyBody   = 1 << 20
+)
 
 type Config struct {
-	DatabaseConfig DatabaseConfig
-	AuthConfig     AuthConfig
+	DatabaseConfig    DatabaseConfig
+	AuthConfig        AuthConfig
+	AssignmentConfig  AssignmentConfig
+	IdempotencyConfig IdempotencyConfig
 }
 
 type DatabaseConfig struct {
@@ -16,11 +32,30 @@ type DatabaseConfig struct {
 	SslMode  string
 }
 
+// reviewerStrategies are the names the selector knows; an empty strategy means
+// least_loaded.
+var reviewerStrategies = []string{"random", "round_robin", "least_loaded"}
+
 type AuthConfig struct {
 	AdminToken string
 	UserToken  string
 }
 
+type AssignmentConfig struct {
+	Strategy                 string
+	TeamStrategies           map[string]string
+	DefaultRequiredReviewers int
+}
+
+type IdempotencyConfig struct {
+	TTL time.Duration
+	// Lease is how long a request without a stored response keeps its key, so
+	// a key left behind by a crashed instance can be retried.
+	Lease time.Duration
+	// MaxBodyBytes limits the body buffered to hash and replay the request.
+	MaxBodyBytes int64
+}
+
 func MustLoad() *Config {
 	return &Config{
 		DatabaseConfig: DatabaseConfig{
@@ -35,5 +70,53 @@ func MustLoad() *Config {
 			AdminToken: os.Getenv("ADMIN_TOKEN"),
 			UserToken:  os.Getenv("USER_TOKEN"),
 		},
+		AssignmentConfig: AssignmentConfig{
+			Strategy:                 mustParseStrategy("REVIEWER_STRATEGY", os.Getenv("REVIEWER_STRATEGY")),
+			TeamStrategies:           parseTeamStrategies(os.Getenv("REVIEWER_STRATEGY_BY_TEAM")),
+			DefaultRequiredReviewers: parsePositiveInt(os.Getenv("DEFAULT_REQUIRED_REVIEWERS"), defaultRequiredReviewers),
+		},
+		IdempotencyConfig: IdempotencyConfig{
+			TTL:          parsePositiveDuration(os.Getenv("IDEMPOTENCY_TTL"), defaultIdempotencyTTL),
+			Lease:        parsePositiveDuration(os.Getenv("IDEMPOTENCY_LEASE"), defaultIdempotencyLease),
+			MaxBodyBytes: int64(parsePositiveInt(os.Getenv("IDEMPOTENCY_MAX_BODY_BYTES"), defaultIdempotencyBody)),
+		},
+	}
+}
+
+// parseTeamStrategies reads "team_a=round_robin,team_b=least_loaded".
+func parseTeamStrategies(raw string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		teamName, strategy, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || teamName == "" {
+			continue
+		}
+		result[teamName] = mustParseStrategy("REVIEWER_STRATEGY_BY_TEAM", strategy)
+	}
+	return result
+}
+
+// mustParseStrategy stops the service on an unknown strategy instead of letting
+// the selector quietly fall back to least_loaded.
+func mustParseStrategy(env, strategy string) string {
+	if strategy != "" && !slices.Contains(reviewerStrategies, strategy) {
+		log.Fatalf("config: %s: unknown reviewer strategy %q, expected one of %s", env, strategy, strings.Join(reviewerStrategies, ", "))
+	}
+	return strategy
+}
+
+func parsePositiveInt(raw string, fallback int) int {
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+func parsePositiveDuration(raw string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(raw)
+	if err != nil || value <= 0 {
+		return fallback
 	}
+	return value
 }
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/internal/app/idempotency"
+	"github.com/leoscrowi/pr-assignment-service/internal/config"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+)
+
+var ErrorResponseIdempotencyMismatch = &domain.ErrorResponse{
+	Code:    domain.IDEMPOTENCY_MISMATCH,
+	Message: "Idempotency-Key was already used with another request",
+}
+
+var ErrorResponseIdempotencyInProgress = &domain.ErrorResponse{
+	Code:    domain.IDEMPOTENCY_IN_PROGRESS,
+	Message: "request with this Idempotency-Key is still in progress",
+}
+
+// IdempotencyMiddleware replays the stored response, headers included, for POST
+// and PATCH requests repeated with the same Idempotency-Key. Server errors and
+// rejected credentials are not stored, so such requests can be retried with the
+// same key. It runs before the per-route auth middleware, which is why 401s
+// have to be skipped here. A reservation left without a response, e.g. by a
+// crashed instance, only blocks the key for the configured lease.
+func IdempotencyMiddleware(repo idempotency.Repository, cfg *config.Config) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" || (r.Method != http.MethodPost && r.Method != http.MethodPatch) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, cfg.IdempotencyConfig.MaxBodyBytes))
+			if err != nil {
+				domain.WriteError(w, domain.NewError(domain.BAD_REQUEST, "bad request", err))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			requestHash := hashRequest(r, body)
+			now := time.Now()
+			record, reserved, err := repo.Reserve(r.Context(), key, requestHash, now.Add(cfg.IdempotencyConfig.TTL), now.Add(-cfg.IdempotencyConfig.Lease))
+			if err != nil {
+				domain.WriteError(w, domain.ConvertToErrorResponse(err))
+				return
+			}
+
+			if !reserved {
+				switch {
+				case record.RequestHash != requestHash:
+					domain.WriteError(w, ErrorResponseIdempotencyMismatch)
+				case record.StatusCode == nil:
+					domain.WriteError(w, ErrorResponseIdempotencyInProgress)
+				default:
+					replayHeaders(w, record.Headers)
+					w.Header().Set(IdempotencyReplayedHeader, "true")
+					w.WriteHeader(*record.StatusCode)
+					_, _ = w.Write(record.Response)
+				}
+				return
+			}
+
+			// The response is stored even if the client has already gone away.
+			ctx := context.WithoutCancel(r.Context())
+			rec := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			defer func() {
+				if p := recover(); p != nil {
+					_ = repo.Release(ctx, key)
+					panic(p)
+				}
+			}()
+
+			next.ServeHTTP(rec, r)
+
+			if rec.statusCode >= http.StatusInternalServerError || rec.statusCode == http.StatusUnauthorized {
+				err = repo.Release(ctx, key)
+			} else {
+				var headers []byte
+				headers, err = json.Marshal(w.Header())
+				if err == nil {
+					err = repo.SaveResponse(ctx, key, rec.statusCode, headers, rec.body.Bytes())
+				}
+			}
+			if err != nil {
+				log.Printf("idempotency: failed to store response for key %s: %v\n", key, err)
+			}
+		})
+	}
+}
+
+// replayHeaders restores the headers of the stored response, ETag and
+// Content-Type among them. Records saved before the headers were kept only
+// get the Content-Type.
+func replayHeaders(w http.ResponseWriter, stored []byte) {
+	var headers http.Header
+	if len(stored) == 0 || json.Unmarshal(stored, &headers) != nil {
+		w.Header().Set("Content-Type", "application/json")
+		return
+	}
+
+	for name, values := range headers {
+		w.Header()[name] = values
+	}
+}
+
+// hashRequest binds the key to the caller, the route with its query and the body.
+func hashRequest(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery + "\n"))
+	h.Write([]byte(r.Header.Get("Authorization") + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	pr_ "github.com/leoscrowi/pr-assignment-service/internal/app/pull_requests/delivery/http/v1"
 	prr_ "github.com/leoscrowi/pr-assignment-service/internal/app/pull_requests/repository/postgresql"
+	prs_ "github.com/leoscrowi/pr-assignment-service/internal/app/pull_requests/selector"
 	prc_ "github.com/leoscrowi/pr-assignment-service/internal/app/pull_requests/usecase"
 	s_ "github.com/leoscrowi/pr-assignment-service/internal/app/stats/delivery/http/v1"
 	sr_ "github.com/leoscrowi/pr-assignment-service/internal/app/stats/repository/postgresql"
@@ -14,17 +15,25 @@ import (
 	u_ "github.com/leoscrowi/pr-assignment-service/internal/app/users/delivery/http/v1"
 	ur_ "github.com/leoscrowi/pr-assignment-service/internal/app/users/repository/postgresql"
 	uc_ "github.com/leoscrowi/pr-assignment-service/internal/app/users/usecase"
+	"github.com/leoscrowi/pr-assignment-service/internal/config"
+	"github.com/leoscrowi/pr-assignment-service/internal/transaction"
 )
 
-func GetControllers(db *sqlx.DB) []RouteSetup {
+func GetControllers(db *sqlx.DB, cfg *config.Config) []RouteSetup {
 	ur := ur_.NewUsersRepository(db)
 	prR := prr_.NewPullRequestsRepository(db)
 	tr := tr_.NewTeamsRepository(db)
 	sr := sr_.NewStatsRepository(db)
 
-	uc := u_.NewUsersController(uc_.NewUsecase(ur, prR))
-	prc := pr_.NewPullRequestController(prc_.NewUsecase(prR, ur))
-	t := t_.NewTeamsController(tc_.NewUsecase(ur, tr))
+	txManager := transaction.NewTxManager(db)
+
+	selector := prs_.NewSelector(cfg.AssignmentConfig)
+
+	prUsecase := prc_.NewUsecase(prR, ur, tr, selector, txManager)
+
+	uc := u_.NewUsersController(uc_.NewUsecase(ur, prR, tr, prUsecase, prUsecase, prUsecase, prUsecase, txManager))
+	prc := pr_.NewPullRequestController(prUsecase)
+	t := t_.NewTeamsController(tc_.NewUsecase(ur, tr, prUsecase, prUsecase, prUsecase, prUsecase, cfg.AssignmentConfig.DefaultRequiredReviewers, txManager))
 	s := s_.NewStatsController(sc_.NewUsecase(sr))
 
 	var res = make([]RouteSetup, 0, 4)
@@ -4,7 +4,9 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jmoiron/sqlx"
+	ir_ "github.com/leoscrowi/pr-assignment-service/internal/app/idempotency/repository/postgresql"
 	"github.com/leoscrowi/pr-assignment-service/internal/config"
+	m_ "github.com/leoscrowi/pr-assignment-service/internal/middleware"
 )
 
 type RouteSetup interface {
@@ -16,15 +18,16 @@ type Server struct {
 	Controllers []RouteSetup
 }
 
-func NewServer(db *sqlx.DB) *Server {
+func NewServer(db *sqlx.DB, cfg *config.Config) *Server {
 	r := chi.NewRouter()
 
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
+	r.Use(m_.IdempotencyMiddleware(ir_.NewIdempotencyRepository(db), cfg))
 
 	return &Server{
 		Router:      r,
-		Controllers: GetControllers(db),
+		Controllers: GetControllers(db, cfg),
 	}
 }
 
@@ -0,0 +1,93 @@
+package transaction
+
+import (
+	"context"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/leoscrowi/pr-assignment-service/domain"
+)
+
+type txKey struct{}
+
+// Manager runs several repository calls as a single unit of work.
+type Manager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type TxManager struct {
+	db *sqlx.DB
+}
+
+func NewTxManager(db *sqlx.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTransaction calls fn with a context carrying an open transaction, which is
+// committed if fn succeeds and rolled back otherwise. Repositories pick it up via Begin.
+// If ctx already carries a transaction, fn joins it.
+func (m *TxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	const op = "transaction.TxManager.WithinTransaction"
+
+	fail := func(code domain.ErrorCode, message string, err error) error {
+		log.Printf("%s: %v\n", op, err)
+		return domain.NewError(code, message, err)
+	}
+
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+	defer func(tx *sqlx.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fail(domain.INTERNAL, "internal server error", err)
+	}
+
+	return nil
+}
+
+// Tx is a transaction used by a single repository call. When the call runs inside
+// WithinTransaction, Commit and Rollback are left to the manager.
+type Tx struct {
+	*sqlx.Tx
+	shared bool
+}
+
+// Begin joins the transaction carried by ctx or starts a new one.
+func Begin(ctx context.Context, db *sqlx.DB) (*Tx, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return &Tx{Tx: tx, shared: true}, nil
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tx{Tx: tx}, nil
+}
+
+func (t *Tx) Commit() error {
+	if t.shared {
+		return nil
+	}
+	return t.Tx.Commit()
+}
+
+func (t *Tx) Rollback() error {
+	if t.shared {
+		return nil
+	}
+	return t.Tx.Rollback()
+}
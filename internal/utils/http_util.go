@@ -3,6 +3,8 @@ package utils
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 func WriteHeader(w http.ResponseWriter, statusCode int, item interface{}) {
@@ -13,3 +15,23 @@ func WriteHeader(w http.ResponseWriter, statusCode int, item interface{}) {
 		return
 	}
 }
+
+// SetETag exposes an entity version as a strong ETag.
+func SetETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// IfMatchVersion returns the version expected by the If-Match header, or nil if
+// the header is absent or "*". A value that is not a version never matches.
+func IfMatchVersion(r *http.Request) *int64 {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(header, `"`), 10, 64)
+	if err != nil {
+		version = -1
+	}
+	return &version
+}
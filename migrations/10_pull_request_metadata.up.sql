@@ -0,0 +1,7 @@
+-- tables
+ALTER TABLE pull_requests
+    ADD COLUMN url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN repository TEXT NOT NULL DEFAULT '',
+    ADD COLUMN source_branch TEXT NOT NULL DEFAULT '',
+    ADD COLUMN target_branch TEXT NOT NULL DEFAULT '',
+    ADD COLUMN description TEXT NOT NULL DEFAULT '';
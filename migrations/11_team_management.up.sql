@@ -0,0 +1,4 @@
+-- tables
+ALTER TABLE users
+    DROP CONSTRAINT users_team_name_fkey,
+    ADD CONSTRAINT users_team_name_fkey FOREIGN KEY (team_name) REFERENCES teams(team_name) ON DELETE SET NULL ON UPDATE CASCADE;
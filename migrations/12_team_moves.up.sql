@@ -0,0 +1,37 @@
+-- tables
+CREATE TABLE team_moves (
+    move_id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    from_team TEXT NULL,
+    to_team TEXT NOT NULL,
+    moved_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- the reviewer's team at the time of assignment, so that reviews stay with the
+-- team they were done for after the reviewer moves
+ALTER TABLE pull_request_reviewers
+    ADD COLUMN reviewer_team_name TEXT NULL REFERENCES teams(team_name) ON DELETE SET NULL ON UPDATE CASCADE;
+
+UPDATE pull_request_reviewers prr
+SET reviewer_team_name = u.team_name
+FROM users u
+WHERE u.user_id = prr.reviewer_id;
+
+-- triggers
+CREATE OR REPLACE FUNCTION set_reviewer_team() RETURNS trigger AS $$
+BEGIN
+    IF NEW.reviewer_team_name IS NULL THEN
+        SELECT team_name INTO NEW.reviewer_team_name FROM users WHERE user_id = NEW.reviewer_id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_rr_reviewer_team
+BEFORE INSERT ON pull_request_reviewers
+FOR EACH ROW
+EXECUTE FUNCTION set_reviewer_team();
+
+-- indexes
+CREATE INDEX idx_tm_user_moved_at ON team_moves (user_id, moved_at);
+CREATE INDEX idx_rr_reviewer_team ON pull_request_reviewers (reviewer_team_name);
@@ -0,0 +1,2 @@
+-- indexes
+CREATE INDEX idx_pr_created_at_range ON pull_requests (created_at);
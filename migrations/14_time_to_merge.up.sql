@@ -0,0 +1,2 @@
+-- indexes
+CREATE INDEX idx_pr_merged_at_range ON pull_requests (merged_at) WHERE status = 'MERGED';
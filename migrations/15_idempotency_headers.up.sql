@@ -0,0 +1,3 @@
+-- tables
+ALTER TABLE idempotency_keys
+    ADD COLUMN response_headers JSONB NULL;
@@ -0,0 +1,26 @@
+-- tables
+-- users removed from a team are recorded as moves to no team
+ALTER TABLE team_moves
+    ALTER COLUMN to_team DROP NOT NULL;
+
+-- triggers
+-- every change of users.team_name is recorded, whichever statement made it; the
+-- update cascaded from renaming a team is not a move, the old team is gone by then
+CREATE OR REPLACE FUNCTION record_team_move() RETURNS trigger AS $$
+BEGIN
+    IF OLD.team_name IS NOT NULL AND NEW.team_name IS NOT NULL
+        AND NOT EXISTS (SELECT 1 FROM teams WHERE team_name = OLD.team_name) THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO team_moves (user_id, from_team, to_team)
+    VALUES (NEW.user_id, OLD.team_name, NEW.team_name);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_users_team_move
+AFTER UPDATE OF team_name ON users
+FOR EACH ROW
+WHEN (OLD.team_name IS DISTINCT FROM NEW.team_name)
+EXECUTE FUNCTION record_team_move();
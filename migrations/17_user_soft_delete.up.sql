@@ -0,0 +1,4 @@
+-- tables
+-- deleted users are kept, so their merged pull requests and reviews stay in place
+ALTER TABLE users
+    ADD COLUMN deleted_at TIMESTAMPTZ NULL;
@@ -0,0 +1,35 @@
+-- tables
+-- the author's team at the time the pull request was created, so that statistics
+-- attribute authored pull requests the same way as reviews
+ALTER TABLE pull_requests
+    ADD COLUMN author_team_name TEXT NULL REFERENCES teams(team_name) ON DELETE SET NULL ON UPDATE CASCADE;
+
+UPDATE pull_requests pr
+SET author_team_name = u.team_name
+FROM users u
+WHERE u.user_id = pr.author_id;
+
+-- reviews assigned before the reviewer's team was recorded
+UPDATE pull_request_reviewers prr
+SET reviewer_team_name = u.team_name
+FROM users u
+WHERE u.user_id = prr.reviewer_id
+  AND prr.reviewer_team_name IS NULL;
+
+-- triggers
+CREATE OR REPLACE FUNCTION set_author_team() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR NEW.author_id IS DISTINCT FROM OLD.author_id THEN
+        SELECT team_name INTO NEW.author_team_name FROM users WHERE user_id = NEW.author_id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_pr_author_team
+BEFORE INSERT OR UPDATE OF author_id ON pull_requests
+FOR EACH ROW
+EXECUTE FUNCTION set_author_team();
+
+-- indexes
+CREATE INDEX idx_pr_author_team ON pull_requests (author_team_name);
@@ -0,0 +1,36 @@
+-- tables
+-- when the pull request last became OPEN, time to merge is measured from it
+ALTER TABLE pull_requests
+    ADD COLUMN ready_at TIMESTAMPTZ NULL;
+
+UPDATE pull_requests
+SET ready_at = created_at
+WHERE status <> 'DRAFT';
+
+-- when the reviewer was assigned, time in review is measured from it
+ALTER TABLE pull_request_reviewers
+    ADD COLUMN assigned_at TIMESTAMPTZ NULL;
+
+UPDATE pull_request_reviewers prr
+SET assigned_at = COALESCE(pr.ready_at, pr.created_at, now())
+FROM pull_requests pr
+WHERE pr.pull_request_id = prr.pull_request_id;
+
+ALTER TABLE pull_request_reviewers
+    ALTER COLUMN assigned_at SET DEFAULT now(),
+    ALTER COLUMN assigned_at SET NOT NULL;
+
+-- triggers
+CREATE OR REPLACE FUNCTION set_ready_at() RETURNS trigger AS $$
+BEGIN
+    IF NEW.status = 'OPEN' AND (TG_OP = 'INSERT' OR OLD.status IS DISTINCT FROM 'OPEN') THEN
+        NEW.ready_at := now();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_pr_ready_at
+BEFORE INSERT OR UPDATE OF status ON pull_requests
+FOR EACH ROW
+EXECUTE FUNCTION set_ready_at();
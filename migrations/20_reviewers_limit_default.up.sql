@@ -0,0 +1,30 @@
+-- tables
+-- teams are always created with required_reviewers from the service config
+-- (DEFAULT_REQUIRED_REVIEWERS), the database keeps no default of its own
+ALTER TABLE teams
+    ALTER COLUMN required_reviewers DROP DEFAULT;
+
+-- triggers
+-- the limit is the one of the author's team; an author without a team has no
+-- limit here, their pull requests are checked by the service only
+CREATE OR REPLACE FUNCTION limit_reviewers() RETURNS trigger AS $$
+DECLARE
+    max_reviewers INTEGER;
+BEGIN
+    SELECT t.required_reviewers INTO max_reviewers
+    FROM pull_requests pr
+    JOIN users u ON u.user_id = pr.author_id
+    JOIN teams t ON t.team_name = u.team_name
+    WHERE pr.pull_request_id = NEW.pull_request_id;
+
+    IF max_reviewers IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    IF (SELECT COUNT(*) FROM pull_request_reviewers
+        WHERE pull_request_id = NEW.pull_request_id) >= max_reviewers THEN
+        RAISE EXCEPTION 'Cannot assign more than % reviewers for a pull request', max_reviewers;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,31 @@
+-- tables
+ALTER TABLE teams
+    ADD COLUMN required_reviewers INTEGER NOT NULL DEFAULT 2 CHECK (required_reviewers > 0);
+
+-- triggers
+CREATE OR REPLACE FUNCTION limit_reviewers() RETURNS trigger AS $$
+DECLARE
+    max_reviewers INTEGER;
+BEGIN
+    SELECT t.required_reviewers INTO max_reviewers
+    FROM pull_requests pr
+    JOIN users u ON u.user_id = pr.author_id
+    JOIN teams t ON t.team_name = u.team_name
+    WHERE pr.pull_request_id = NEW.pull_request_id;
+
+    max_reviewers := COALESCE(max_reviewers, 2);
+
+    IF (SELECT COUNT(*) FROM pull_request_reviewers
+        WHERE pull_request_id = NEW.pull_request_id) >= max_reviewers THEN
+    RAISE EXCEPTION 'Cannot assign more than % reviewers for a pull request', max_reviewers;
+END IF;
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER trg_limit_reviewers ON pull_request_reviewers;
+
+CREATE TRIGGER trg_limit_reviewers
+BEFORE INSERT ON pull_request_reviewers
+FOR EACH ROW
+EXECUTE FUNCTION limit_reviewers();
@@ -0,0 +1,11 @@
+-- tables
+CREATE TABLE team_fallbacks (
+    team_name TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE ON UPDATE CASCADE,
+    fallback_team_name TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE ON UPDATE CASCADE,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (team_name, fallback_team_name),
+    CHECK (team_name <> fallback_team_name)
+);
+
+-- indexes
+CREATE INDEX idx_tf_team_position ON team_fallbacks (team_name, position);
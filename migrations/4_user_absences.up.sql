@@ -0,0 +1,12 @@
+-- tables
+CREATE TABLE user_absences (
+    absence_id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    CHECK (ends_at > starts_at)
+);
+
+-- indexes
+CREATE INDEX idx_ua_user_period ON user_absences (user_id, starts_at, ends_at);
@@ -0,0 +1,6 @@
+-- tables
+ALTER TABLE teams
+    ADD COLUMN max_open_reviews INTEGER NULL CHECK (max_open_reviews > 0);
+
+ALTER TABLE users
+    ADD COLUMN max_open_reviews INTEGER NULL CHECK (max_open_reviews > 0);
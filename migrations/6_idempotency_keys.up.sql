@@ -0,0 +1,12 @@
+-- tables
+CREATE TABLE idempotency_keys (
+    idempotency_key TEXT PRIMARY KEY,
+    request_hash TEXT NOT NULL,
+    status_code INTEGER NULL,
+    response_body BYTEA NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+-- indexes
+CREATE INDEX idx_ik_expires_at ON idempotency_keys (expires_at);
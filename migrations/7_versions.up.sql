@@ -0,0 +1,72 @@
+-- tables
+ALTER TABLE pull_requests
+    ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+ALTER TABLE teams
+    ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+-- triggers
+CREATE OR REPLACE FUNCTION bump_version() RETURNS trigger AS $$
+BEGIN
+    IF NEW.version = OLD.version THEN
+        NEW.version := OLD.version + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_pr_version
+BEFORE UPDATE ON pull_requests
+FOR EACH ROW
+EXECUTE FUNCTION bump_version();
+
+CREATE TRIGGER trg_team_version
+BEFORE UPDATE ON teams
+FOR EACH ROW
+EXECUTE FUNCTION bump_version();
+
+-- reviewers belong to the pull request, so changing them changes its version
+CREATE OR REPLACE FUNCTION bump_pr_version_on_reviewers() RETURNS trigger AS $$
+BEGIN
+    UPDATE pull_requests SET version = version + 1
+    WHERE pull_request_id = COALESCE(NEW.pull_request_id, OLD.pull_request_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_rr_pr_version
+AFTER INSERT OR DELETE ON pull_request_reviewers
+FOR EACH ROW
+EXECUTE FUNCTION bump_pr_version_on_reviewers();
+
+-- fallbacks and members are part of the team as returned by /team/get
+CREATE OR REPLACE FUNCTION bump_team_version_on_fallbacks() RETURNS trigger AS $$
+BEGIN
+    UPDATE teams SET version = version + 1
+    WHERE team_name = COALESCE(NEW.team_name, OLD.team_name);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_tf_team_version
+AFTER INSERT OR DELETE ON team_fallbacks
+FOR EACH ROW
+EXECUTE FUNCTION bump_team_version_on_fallbacks();
+
+CREATE OR REPLACE FUNCTION bump_team_version_on_members() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.team_name IS NOT NULL THEN
+        UPDATE teams SET version = version + 1 WHERE team_name = OLD.team_name;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.team_name IS NOT NULL
+        AND (TG_OP = 'INSERT' OR NEW.team_name IS DISTINCT FROM OLD.team_name) THEN
+        UPDATE teams SET version = version + 1 WHERE team_name = NEW.team_name;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_users_team_version
+AFTER INSERT OR DELETE OR UPDATE OF team_name, username, is_active ON users
+FOR EACH ROW
+EXECUTE FUNCTION bump_team_version_on_members();
@@ -0,0 +1,4 @@
+-- indexes
+CREATE INDEX idx_pr_created_at ON pull_requests ((COALESCE(created_at, '0001-01-01 00:00:00+00'::timestamptz)), pull_request_id);
+CREATE INDEX idx_pr_merged_at ON pull_requests ((COALESCE(merged_at, '0001-01-01 00:00:00+00'::timestamptz)), pull_request_id);
+CREATE INDEX idx_users_team_name ON users (team_name);
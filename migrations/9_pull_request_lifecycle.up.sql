@@ -0,0 +1,5 @@
+-- tables
+ALTER TABLE pull_requests
+    DROP CONSTRAINT pull_requests_status_check,
+    ADD CONSTRAINT pull_requests_status_check CHECK (status IN ('DRAFT', 'OPEN', 'MERGED', 'CLOSED')),
+    ADD COLUMN closed_at TIMESTAMPTZ NULL;
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestETag_TeamUpdate(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_etag_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_etag_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_etag_u2", "username": "TestBob", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	respGet := helpers.GetJSON(t, "/team/get/test_etag_team", nil, helpers.AdminToken)
+	_ = respGet.Body.Close()
+	helpers.RequireStatusCode(t, respGet, http.StatusOK)
+	etag := respGet.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+
+	update := map[string]interface{}{
+		"team_name":          "test_etag_team",
+		"required_reviewers": 1,
+	}
+
+	respUpdate := helpers.DoJSON(t, http.MethodPatch, "/team/update", update, helpers.AdminToken, map[string]string{"If-Match": etag})
+	_ = respUpdate.Body.Close()
+	helpers.RequireStatusCode(t, respUpdate, http.StatusOK)
+	assert.NotEqual(t, etag, respUpdate.Header.Get("ETag"), "version should change after update")
+
+	update["required_reviewers"] = 2
+	respStale := helpers.DoJSON(t, http.MethodPatch, "/team/update", update, helpers.AdminToken, map[string]string{"If-Match": etag})
+	_ = respStale.Body.Close()
+	helpers.RequireStatusCode(t, respStale, http.StatusPreconditionFailed)
+}
+
+func TestETag_PullRequestMerge(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_etag_pr_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_etag_pr_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_etag_pr_u2", "username": "TestBob", "is_active": true},
+			{"user_id": "test_etag_pr_u3", "username": "TestCharlie", "is_active": true},
+			{"user_id": "test_etag_pr_u4", "username": "TestSarah", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	pr := map[string]interface{}{
+		"pull_request_id":   "test_etag_pr",
+		"pull_request_name": "ETag PR",
+		"author_id":         "test_etag_pr_u1",
+	}
+
+	respCreate := helpers.PostJSON(t, "/pullRequest/create", pr, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respCreate.Body)
+	helpers.RequireStatusCode(t, respCreate, http.StatusCreated)
+	etag := respCreate.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+
+	var created struct {
+		PR domain.PullRequest `json:"pr"`
+	}
+	require.NoError(t, json.NewDecoder(respCreate.Body).Decode(&created), "decode response")
+	require.NotEmpty(t, created.PR.AssignedReviewers)
+	assert.Equal(t, fmt.Sprintf("%q", strconv.FormatInt(created.PR.Version, 10)), etag)
+
+	respReassign := helpers.DoJSON(t, http.MethodPatch, "/pullRequest/reassign", map[string]interface{}{
+		"pull_request_id": "test_etag_pr",
+		"old_user_id":     created.PR.AssignedReviewers[0],
+	}, helpers.AdminToken, map[string]string{"If-Match": etag})
+	_ = respReassign.Body.Close()
+	helpers.RequireStatusCode(t, respReassign, http.StatusOK)
+	current := respReassign.Header.Get("ETag")
+
+	merge := map[string]interface{}{"pull_request_id": "test_etag_pr"}
+
+	respStale := helpers.DoJSON(t, http.MethodPatch, "/pullRequest/merge", merge, helpers.AdminToken, map[string]string{"If-Match": etag})
+	_ = respStale.Body.Close()
+	helpers.RequireStatusCode(t, respStale, http.StatusPreconditionFailed)
+
+	respMerge := helpers.DoJSON(t, http.MethodPatch, "/pullRequest/merge", merge, helpers.AdminToken, map[string]string{"If-Match": current})
+	_ = respMerge.Body.Close()
+	helpers.RequireStatusCode(t, respMerge, http.StatusOK)
+}
@@ -0,0 +1,68 @@
+package helpers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+)
+
+// AddTeam creates a team with the given active members.
+func AddTeam(t *testing.T, teamName string, userIDs ...string) {
+	t.Helper()
+
+	members := make([]map[string]interface{}, 0, len(userIDs))
+	for _, userID := range userIDs {
+		members = append(members, map[string]interface{}{"user_id": userID, "username": userID, "is_active": true})
+	}
+
+	resp := PostJSON(t, "/team/add", map[string]interface{}{"team_name": teamName, "members": members}, AdminToken)
+	_ = resp.Body.Close()
+	RequireStatusCode(t, resp, http.StatusCreated)
+}
+
+// CreatePullRequest creates an OPEN pull request named after its id and returns it
+// with the assigned reviewers.
+func CreatePullRequest(t *testing.T, prID, authorID string) domain.PullRequest {
+	t.Helper()
+
+	pr := map[string]interface{}{
+		"pull_request_id":   prID,
+		"pull_request_name": prID,
+		"author_id":         authorID,
+	}
+
+	resp := PostJSON(t, "/pullRequest/create", pr, AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+	RequireStatusCode(t, resp, http.StatusCreated)
+
+	var out struct {
+		PR domain.PullRequest `json:"pr"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return out.PR
+}
+
+// GetStats fetches a stats endpoint and decodes its "stats" field into stats.
+func GetStats(t *testing.T, path string, stats interface{}) {
+	t.Helper()
+
+	resp := GetJSON(t, path, nil, UserToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+	RequireStatusCode(t, resp, http.StatusOK)
+
+	out := struct {
+		Stats interface{} `json:"stats"`
+	}{Stats: stats}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+}
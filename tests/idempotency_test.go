@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotency_ReplaysCreate(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_idem_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_idem_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_idem_u2", "username": "TestBob", "is_active": true},
+			{"user_id": "test_idem_u3", "username": "TestCharlie", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	pr := map[string]interface{}{
+		"pull_request_id":   "test_idem_pr",
+		"pull_request_name": "Idempotent PR",
+		"author_id":         "test_idem_u1",
+	}
+	headers := map[string]string{"Idempotency-Key": "test-idem-create"}
+
+	respFirst := helpers.DoJSON(t, http.MethodPost, "/pullRequest/create", pr, helpers.AdminToken, headers)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respFirst.Body)
+	helpers.RequireStatusCode(t, respFirst, http.StatusCreated)
+	first := helpers.ReadBody(t, respFirst)
+
+	respReplay := helpers.DoJSON(t, http.MethodPost, "/pullRequest/create", pr, helpers.AdminToken, headers)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respReplay.Body)
+	helpers.RequireStatusCode(t, respReplay, http.StatusCreated)
+	assert.Equal(t, "true", respReplay.Header.Get("Idempotent-Replayed"))
+	assert.JSONEq(t, string(first), string(helpers.ReadBody(t, respReplay)), "replay should return the original response")
+
+	pr["pull_request_name"] = "Another PR"
+	respMismatch := helpers.DoJSON(t, http.MethodPost, "/pullRequest/create", pr, helpers.AdminToken, headers)
+	_ = respMismatch.Body.Close()
+	helpers.RequireStatusCode(t, respMismatch, http.StatusUnprocessableEntity)
+}
+
+func TestIdempotency_ReassignSwapsOnce(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_idem_reassign_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_idr_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_idr_u2", "username": "TestBob", "is_active": true},
+			{"user_id": "test_idr_u3", "username": "TestCharlie", "is_active": true},
+			{"user_id": "test_idr_u4", "username": "TestSarah", "is_active": true},
+			{"user_id": "test_idr_u5", "username": "TestDave", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	pr := helpers.CreatePullRequest(t, "test_idr_pr", "test_idr_u1")
+	require.Len(t, pr.AssignedReviewers, 2)
+
+	reassign := map[string]interface{}{
+		"pull_request_id": "test_idr_pr",
+		"old_user_id":     pr.AssignedReviewers[0],
+	}
+	headers := map[string]string{"Idempotency-Key": "test-idem-reassign"}
+
+	var results [2]struct {
+		PR         domain.PullRequest `json:"pr"`
+		ReplacedBy string             `json:"replaced_by"`
+	}
+	for i := range results {
+		resp := helpers.DoJSON(t, http.MethodPatch, "/pullRequest/reassign", reassign, helpers.AdminToken, headers)
+		helpers.RequireStatusCode(t, resp, http.StatusOK)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&results[i]), "decode response")
+		_ = resp.Body.Close()
+	}
+
+	assert.Equal(t, results[0].ReplacedBy, results[1].ReplacedBy)
+
+	respMerge := helpers.PatchJSON(t, "/pullRequest/merge", map[string]interface{}{"pull_request_id": "test_idr_pr"}, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respMerge.Body)
+	helpers.RequireStatusCode(t, respMerge, http.StatusOK)
+
+	var merged struct {
+		PR domain.PullRequest `json:"pr"`
+	}
+	require.NoError(t, json.NewDecoder(respMerge.Body).Decode(&merged), "decode response")
+	assert.ElementsMatch(t, results[0].PR.AssignedReviewers, merged.PR.AssignedReviewers, "replay must not swap a second reviewer")
+}
+
+func TestIdempotency_ReplaysHeadersAndSkipsUnauthorized(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_idem_headers_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_idh_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_idh_u2", "username": "TestBob", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	update := map[string]interface{}{
+		"team_name":          "test_idem_headers_team",
+		"required_reviewers": 1,
+	}
+	headers := map[string]string{"Idempotency-Key": "test-idem-headers"}
+
+	respAnonymous := helpers.DoJSON(t, http.MethodPatch, "/team/update", update, "", headers)
+	_ = respAnonymous.Body.Close()
+	helpers.RequireStatusCode(t, respAnonymous, http.StatusUnauthorized)
+
+	respFirst := helpers.DoJSON(t, http.MethodPatch, "/team/update", update, helpers.AdminToken, headers)
+	_ = respFirst.Body.Close()
+	helpers.RequireStatusCode(t, respFirst, http.StatusOK)
+	require.NotEmpty(t, respFirst.Header.Get("ETag"))
+
+	respReplay := helpers.DoJSON(t, http.MethodPatch, "/team/update", update, helpers.AdminToken, headers)
+	_ = respReplay.Body.Close()
+	helpers.RequireStatusCode(t, respReplay, http.StatusOK)
+	assert.Equal(t, "true", respReplay.Header.Get("Idempotent-Replayed"))
+	assert.Equal(t, respFirst.Header.Get("ETag"), respReplay.Header.Get("ETag"))
+	assert.Equal(t, respFirst.Header.Get("Content-Type"), respReplay.Header.Get("Content-Type"))
+}
+
+func TestIdempotency_QueryIsPartOfRequest(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_idem_query_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_idq_u1", "username": "TestAlice", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	body := map[string]interface{}{"user_id": "test_idq_u1", "is_active": false}
+	headers := map[string]string{"Idempotency-Key": "test-idem-query"}
+
+	respFirst := helpers.DoJSON(t, http.MethodPatch, "/users/setIsActive", body, helpers.AdminToken, headers)
+	_ = respFirst.Body.Close()
+	helpers.RequireStatusCode(t, respFirst, http.StatusOK)
+
+	respOther := helpers.DoJSON(t, http.MethodPatch, "/users/setIsActive?reassign=true", body, helpers.AdminToken, headers)
+	_ = respOther.Body.Close()
+	helpers.RequireStatusCode(t, respOther, http.StatusUnprocessableEntity)
+}
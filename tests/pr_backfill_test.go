@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullRequestBackfill_OnActivation(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_bf_activation",
+		"members": []map[string]interface{}{
+			{"user_id": "test_bf_act_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_bf_act_u2", "username": "TestBob", "is_active": true},
+			{"user_id": "test_bf_act_u3", "username": "TestCharlie", "is_active": false},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	pr := map[string]interface{}{
+		"pull_request_id":   "test_bf_act_pr",
+		"pull_request_name": "Understaffed",
+		"author_id":         "test_bf_act_u1",
+	}
+
+	respCreate := helpers.PostJSON(t, "/pullRequest/create", pr, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respCreate.Body)
+	helpers.RequireStatusCode(t, respCreate, http.StatusCreated)
+
+	var created struct {
+		PR domain.PullRequest `json:"pr"`
+	}
+	require.NoError(t, json.NewDecoder(respCreate.Body).Decode(&created), "decode response")
+	require.True(t, created.PR.NeedMoreReviewers)
+
+	activate := map[string]interface{}{
+		"user_id":   "test_bf_act_u3",
+		"is_active": true,
+	}
+
+	respActivate := helpers.PatchJSON(t, "/users/setIsActive", activate, helpers.AdminToken)
+	_ = respActivate.Body.Close()
+	helpers.RequireStatusCode(t, respActivate, http.StatusOK)
+
+	respReview := helpers.GetJSON(t, "/users/getReview/test_bf_act_u3", nil, helpers.UserToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respReview.Body)
+	helpers.RequireStatusCode(t, respReview, http.StatusOK)
+
+	var review struct {
+		PullRequests []domain.PullRequestShort `json:"pull_requests"`
+	}
+	require.NoError(t, json.NewDecoder(respReview.Body).Decode(&review), "decode response")
+
+	require.Len(t, review.PullRequests, 1, "activated user should be assigned to the understaffed PR")
+	assert.Equal(t, "test_bf_act_pr", review.PullRequests[0].PullRequestID)
+}
+
+func TestPullRequestBackfill_OnDemand(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_bf_demand",
+		"members": []map[string]interface{}{
+			{"user_id": "test_bf_dem_u1", "username": "TestAlice", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	pr := map[string]interface{}{
+		"pull_request_id":   "test_bf_dem_pr",
+		"pull_request_name": "Lonely",
+		"author_id":         "test_bf_dem_u1",
+	}
+
+	respCreate := helpers.PostJSON(t, "/pullRequest/create", pr, helpers.AdminToken)
+	_ = respCreate.Body.Close()
+	helpers.RequireStatusCode(t, respCreate, http.StatusCreated)
+
+	helperTeam := map[string]interface{}{
+		"team_name": "test_bf_demand_helpers",
+		"members": []map[string]interface{}{
+			{"user_id": "test_bf_dem_h1", "username": "TestHelperOne", "is_active": true},
+			{"user_id": "test_bf_dem_h2", "username": "TestHelperTwo", "is_active": true},
+		},
+	}
+
+	respHelpers := helpers.PostJSON(t, "/team/add", helperTeam, helpers.AdminToken)
+	_ = respHelpers.Body.Close()
+	helpers.RequireStatusCode(t, respHelpers, http.StatusCreated)
+
+	update := map[string]interface{}{
+		"team_name":      "test_bf_demand",
+		"fallback_teams": []string{"test_bf_demand_helpers"},
+	}
+
+	respUpdate := helpers.PatchJSON(t, "/team/update", update, helpers.AdminToken)
+	_ = respUpdate.Body.Close()
+	helpers.RequireStatusCode(t, respUpdate, http.StatusOK)
+
+	respBackfill := helpers.PostJSON(t, "/pullRequest/backfill", map[string]interface{}{"team_name": "test_bf_demand"}, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respBackfill.Body)
+	helpers.RequireStatusCode(t, respBackfill, http.StatusOK)
+
+	var out struct {
+		Filled []domain.BackfillResult `json:"filled"`
+	}
+	require.NoError(t, json.NewDecoder(respBackfill.Body).Decode(&out), "decode response")
+
+	require.Len(t, out.Filled, 1)
+	assert.Equal(t, "test_bf_dem_pr", out.Filled[0].PullRequestID)
+	assert.ElementsMatch(t, []string{"test_bf_dem_h1", "test_bf_dem_h2"}, out.Filled[0].AddedReviewers)
+	assert.False(t, out.Filled[0].NeedMoreReviewers)
+}
+
+func TestPullRequestBackfill_Unauthorized(t *testing.T) {
+	resp := helpers.PostJSON(t, "/pullRequest/backfill", map[string]interface{}{}, helpers.UserToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
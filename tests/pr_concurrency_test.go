@@ -0,0 +1,253 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullRequestConcurrency_ReassignHammer(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_hammer_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_hm_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_hm_u2", "username": "TestBob", "is_active": true},
+			{"user_id": "test_hm_u3", "username": "TestCharlie", "is_active": true},
+			{"user_id": "test_hm_u4", "username": "TestSarah", "is_active": true},
+			{"user_id": "test_hm_u5", "username": "TestDave", "is_active": true},
+			{"user_id": "test_hm_u6", "username": "TestEve", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	pr := helpers.CreatePullRequest(t, "test_hm_pr", "test_hm_u1")
+	require.Len(t, pr.AssignedReviewers, 2)
+
+	const workers = 20
+	reviewers := []string{"test_hm_u2", "test_hm_u3", "test_hm_u4", "test_hm_u5", "test_hm_u6"}
+
+	var wg sync.WaitGroup
+	statuses := make(chan int, workers+1)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(oldUserID string) {
+			defer wg.Done()
+
+			resp := helpers.PatchJSON(t, "/pullRequest/reassign", map[string]interface{}{
+				"pull_request_id": "test_hm_pr",
+				"old_user_id":     oldUserID,
+			}, helpers.AdminToken)
+			_ = resp.Body.Close()
+			statuses <- resp.StatusCode
+		}(reviewers[i%len(reviewers)])
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		resp := helpers.PatchJSON(t, "/pullRequest/merge", map[string]interface{}{"pull_request_id": "test_hm_pr"}, helpers.AdminToken)
+		_ = resp.Body.Close()
+		statuses <- resp.StatusCode
+	}()
+
+	wg.Wait()
+	close(statuses)
+
+	for status := range statuses {
+		assert.Contains(t, []int{http.StatusOK, http.StatusNotFound, http.StatusConflict}, status, "unexpected status under concurrency")
+	}
+
+	respMerge := helpers.PatchJSON(t, "/pullRequest/merge", map[string]interface{}{"pull_request_id": "test_hm_pr"}, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respMerge.Body)
+	helpers.RequireStatusCode(t, respMerge, http.StatusOK)
+
+	var out struct {
+		PR domain.PullRequest `json:"pr"`
+	}
+	require.NoError(t, json.NewDecoder(respMerge.Body).Decode(&out), "decode response")
+
+	assert.Equal(t, domain.MERGED, out.PR.Status)
+	assert.Len(t, out.PR.AssignedReviewers, 2, "reviewers must not be lost or duplicated")
+	assert.NotContains(t, out.PR.AssignedReviewers, "test_hm_u1", "author must not be assigned")
+
+	sorted := slices.Clone(out.PR.AssignedReviewers)
+	slices.Sort(sorted)
+	assert.Len(t, slices.Compact(sorted), 2, "reviewers must be distinct")
+}
+
+func TestPullRequestConcurrency_ReassignRespectsCapacity(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name":        "test_hammer_cap_team",
+		"max_open_reviews": 1,
+		"members": []map[string]interface{}{
+			{"user_id": "test_hmc_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_hmc_u2", "username": "TestBob", "is_active": true},
+			{"user_id": "test_hmc_u3", "username": "TestCharlie", "is_active": true},
+			{"user_id": "test_hmc_u4", "username": "TestSarah", "is_active": true},
+			{"user_id": "test_hmc_u5", "username": "TestDave", "is_active": true},
+			{"user_id": "test_hmc_u6", "username": "TestEve", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	first := helpers.CreatePullRequest(t, "test_hmc_pr1", "test_hmc_u1")
+	require.Len(t, first.AssignedReviewers, 2)
+	second := helpers.CreatePullRequest(t, "test_hmc_pr2", "test_hmc_u1")
+	require.Len(t, second.AssignedReviewers, 2)
+
+	// One member is left with a free slot, both reassigns compete for it.
+	var wg sync.WaitGroup
+	statuses := make(chan int, 2)
+	for _, pr := range []domain.PullRequest{first, second} {
+		wg.Add(1)
+		go func(pr domain.PullRequest) {
+			defer wg.Done()
+
+			resp := helpers.PatchJSON(t, "/pullRequest/reassign", map[string]interface{}{
+				"pull_request_id": pr.PullRequestID,
+				"old_user_id":     pr.AssignedReviewers[0],
+			}, helpers.AdminToken)
+			_ = resp.Body.Close()
+			statuses <- resp.StatusCode
+		}(pr)
+	}
+
+	wg.Wait()
+	close(statuses)
+
+	for status := range statuses {
+		assert.Contains(t, []int{http.StatusOK, http.StatusConflict}, status, "unexpected status under concurrency")
+	}
+
+	var stats []domain.PullRequestStats
+	helpers.GetStats(t, "/stats/users?team_name=test_hammer_cap_team", &stats)
+	for _, st := range stats {
+		assert.LessOrEqual(t, st.CurrentLoad, 1, "capacity exceeded for %s", st.UserID)
+	}
+}
+
+func TestPullRequestConcurrency_DeactivateWithReassign(t *testing.T) {
+	helpers.AddTeam(t, "test_lockorder_team", "test_lo_u1", "test_lo_u2", "test_lo_u3", "test_lo_u4", "test_lo_u5", "test_lo_u6")
+
+	const prs = 5
+	for i := 0; i < prs; i++ {
+		helpers.CreatePullRequest(t, fmt.Sprintf("test_lo_pr_%d", i), "test_lo_u1")
+	}
+
+	var wg sync.WaitGroup
+	statuses := make(chan int, 2*prs+1)
+	for i := 0; i < prs; i++ {
+		wg.Add(1)
+		go func(prID string) {
+			defer wg.Done()
+
+			resp := helpers.PatchJSON(t, "/pullRequest/reassign", map[string]interface{}{
+				"pull_request_id": prID,
+				"old_user_id":     "test_lo_u2",
+			}, helpers.AdminToken)
+			_ = resp.Body.Close()
+			statuses <- resp.StatusCode
+		}(fmt.Sprintf("test_lo_pr_%d", i))
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		resp := helpers.PostJSON(t, "/team/deactivateMembers", map[string]interface{}{
+			"team_name": "test_lockorder_team",
+			"user_ids":  []string{"test_lo_u2", "test_lo_u3"},
+		}, helpers.AdminToken)
+		_ = resp.Body.Close()
+		statuses <- resp.StatusCode
+	}()
+
+	wg.Wait()
+	close(statuses)
+
+	for status := range statuses {
+		assert.Contains(t, []int{http.StatusOK, http.StatusNotFound, http.StatusConflict}, status, "deadlocks surface as 500")
+	}
+}
+
+func TestPullRequestConcurrency_FallbackRespectsCapacity(t *testing.T) {
+	fallback := map[string]interface{}{
+		"team_name":        "test_hammer_fb_helpers",
+		"max_open_reviews": 1,
+		"members": []map[string]interface{}{
+			{"user_id": "test_hmf_h1", "username": "TestHelperOne", "is_active": true},
+			{"user_id": "test_hmf_h2", "username": "TestHelperTwo", "is_active": true},
+		},
+	}
+
+	respFallback := helpers.PostJSON(t, "/team/add", fallback, helpers.AdminToken)
+	_ = respFallback.Body.Close()
+	helpers.RequireStatusCode(t, respFallback, http.StatusCreated)
+
+	// Two single-member teams draw every reviewer from the shared fallback team.
+	authors := []string{"test_hmf_a1", "test_hmf_a2"}
+	for i, author := range authors {
+		team := map[string]interface{}{
+			"team_name":      fmt.Sprintf("test_hammer_fb_home_%d", i),
+			"fallback_teams": []string{"test_hammer_fb_helpers"},
+			"members": []map[string]interface{}{
+				{"user_id": author, "username": "TestAuthor", "is_active": true},
+			},
+		}
+
+		respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+		_ = respAdd.Body.Close()
+		helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+	}
+
+	const perAuthor = 3
+	var wg sync.WaitGroup
+	statuses := make(chan int, perAuthor*len(authors))
+	for i := 0; i < perAuthor; i++ {
+		for _, author := range authors {
+			wg.Add(1)
+			go func(prID, author string) {
+				defer wg.Done()
+
+				resp := helpers.PostJSON(t, "/pullRequest/create", map[string]interface{}{
+					"pull_request_id":   prID,
+					"pull_request_name": "Hammer",
+					"author_id":         author,
+				}, helpers.AdminToken)
+				_ = resp.Body.Close()
+				statuses <- resp.StatusCode
+			}(fmt.Sprintf("test_hmf_pr_%s_%d", author, i), author)
+		}
+	}
+
+	wg.Wait()
+	close(statuses)
+
+	for status := range statuses {
+		assert.Contains(t, []int{http.StatusCreated, http.StatusConflict}, status, "unexpected status under concurrency")
+	}
+
+	var stats []domain.PullRequestStats
+	helpers.GetStats(t, "/stats/users?team_name=test_hammer_fb_helpers", &stats)
+	for _, st := range stats {
+		assert.LessOrEqual(t, st.CurrentLoad, 1, "capacity exceeded for %s", st.UserID)
+	}
+}
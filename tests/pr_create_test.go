@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"slices"
 	"testing"
 
 	"github.com/leoscrowi/pr-assignment-service/domain"
@@ -62,8 +63,9 @@ func TestPullRequestCreate_Success(t *testing.T) {
 		assert.NotEqual(t, "test_u1", reviewer, "author should not be assigned as reviewer")
 	}
 
-	expectedReviewers := []string{"test_u2", "test_u3"}
-	assert.ElementsMatch(t, expectedReviewers, result.PR.AssignedReviewers, "reviewers should match expected")
+	for _, reviewer := range result.PR.AssignedReviewers {
+		assert.Contains(t, []string{"test_u2", "test_u3", "test_u4"}, reviewer, "reviewer should be from author's team")
+	}
 }
 
 func TestPullRequestCreate_AuthorNotFound(t *testing.T) {
@@ -182,3 +184,62 @@ func TestPullRequestCreate_NotEnoughReviewers(t *testing.T) {
 	require.NoError(t, json.NewDecoder(respCreate.Body).Decode(&result), "decode response")
 	assert.Empty(t, result.PR.AssignedReviewers, "should have 0 assigned reviewers in solo team")
 }
+
+func TestPullRequestCreate_PrefersLeastLoadedReviewers(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_least_loaded_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_ll_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_ll_u2", "username": "TestBob", "is_active": true},
+			{"user_id": "test_ll_u3", "username": "TestCharlie", "is_active": true},
+			{"user_id": "test_ll_u4", "username": "TestSarah", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	var result struct {
+		PR struct {
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		} `json:"pr"`
+	}
+
+	first := map[string]interface{}{
+		"pull_request_id":   "test_ll_pr_1",
+		"pull_request_name": "First PR",
+		"author_id":         "test_ll_u1",
+	}
+
+	respFirst := helpers.PostJSON(t, "/pullRequest/create", first, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respFirst.Body)
+	helpers.RequireStatusCode(t, respFirst, http.StatusCreated)
+	require.NoError(t, json.NewDecoder(respFirst.Body).Decode(&result), "decode response")
+	require.Len(t, result.PR.AssignedReviewers, 2)
+	busy := result.PR.AssignedReviewers
+
+	second := map[string]interface{}{
+		"pull_request_id":   "test_ll_pr_2",
+		"pull_request_name": "Second PR",
+		"author_id":         "test_ll_u1",
+	}
+
+	respSecond := helpers.PostJSON(t, "/pullRequest/create", second, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respSecond.Body)
+	helpers.RequireStatusCode(t, respSecond, http.StatusCreated)
+	require.NoError(t, json.NewDecoder(respSecond.Body).Decode(&result), "decode response")
+	require.Len(t, result.PR.AssignedReviewers, 2)
+
+	var idle string
+	for _, id := range []string{"test_ll_u2", "test_ll_u3", "test_ll_u4"} {
+		if !slices.Contains(busy, id) {
+			idle = id
+		}
+	}
+	assert.Contains(t, result.PR.AssignedReviewers, idle, "reviewer without open reviews should be picked first")
+}
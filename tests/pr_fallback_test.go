@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullRequestFallback_CreateAndReassign(t *testing.T) {
+	fallbackTeam := map[string]interface{}{
+		"team_name": "test_fb_helpers",
+		"members": []map[string]interface{}{
+			{"user_id": "test_fb_h1", "username": "TestHelperOne", "is_active": true},
+			{"user_id": "test_fb_h2", "username": "TestHelperTwo", "is_active": true},
+		},
+	}
+
+	respFallback := helpers.PostJSON(t, "/team/add", fallbackTeam, helpers.AdminToken)
+	_ = respFallback.Body.Close()
+	helpers.RequireStatusCode(t, respFallback, http.StatusCreated)
+
+	team := map[string]interface{}{
+		"team_name":      "test_fb_small",
+		"fallback_teams": []string{"test_fb_helpers"},
+		"members": []map[string]interface{}{
+			{"user_id": "test_fb_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_fb_u2", "username": "TestBob", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	pr := map[string]interface{}{
+		"pull_request_id":   "test_fb_pr_1",
+		"pull_request_name": "Needs help",
+		"author_id":         "test_fb_u1",
+	}
+
+	respCreate := helpers.PostJSON(t, "/pullRequest/create", pr, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respCreate.Body)
+	helpers.RequireStatusCode(t, respCreate, http.StatusCreated)
+
+	var created struct {
+		PR domain.PullRequest `json:"pr"`
+	}
+	require.NoError(t, json.NewDecoder(respCreate.Body).Decode(&created), "decode response")
+
+	require.Len(t, created.PR.AssignedReviewers, 2)
+	assert.Contains(t, created.PR.AssignedReviewers, "test_fb_u2", "home team member goes first")
+	assert.False(t, created.PR.NeedMoreReviewers)
+	require.Len(t, created.PR.FallbackReviewers, 1)
+
+	fallbackReviewer := created.PR.FallbackReviewers[0]
+	assert.Contains(t, []string{"test_fb_h1", "test_fb_h2"}, fallbackReviewer)
+
+	reassign := map[string]interface{}{
+		"pull_request_id": "test_fb_pr_1",
+		"old_user_id":     fallbackReviewer,
+	}
+
+	respReassign := helpers.PatchJSON(t, "/pullRequest/reassign", reassign, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respReassign.Body)
+	helpers.RequireStatusCode(t, respReassign, http.StatusOK)
+
+	var reassigned struct {
+		PR         domain.PullRequest `json:"pr"`
+		ReplacedBy string             `json:"replaced_by"`
+	}
+	require.NoError(t, json.NewDecoder(respReassign.Body).Decode(&reassigned), "decode response")
+
+	assert.NotEqual(t, fallbackReviewer, reassigned.ReplacedBy)
+	assert.Contains(t, []string{"test_fb_h1", "test_fb_h2"}, reassigned.ReplacedBy)
+	assert.Equal(t, []string{reassigned.ReplacedBy}, reassigned.PR.FallbackReviewers)
+}
+
+func TestPullRequestFallback_UnknownFallbackTeam(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name":      "test_fb_unknown",
+		"fallback_teams": []string{"test_fb_missing"},
+		"members": []map[string]interface{}{
+			{"user_id": "test_fb_unknown_u1", "username": "TestAlice", "is_active": true},
+		},
+	}
+
+	resp := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	helpers.RequireStatusCode(t, resp, http.StatusNotFound)
+}
@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func changeStatus(t *testing.T, path, prID string, expected int) domain.PullRequest {
+	t.Helper()
+
+	resp := helpers.PatchJSON(t, path, map[string]interface{}{"pull_request_id": prID}, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+	helpers.RequireStatusCode(t, resp, expected)
+
+	var out struct {
+		PR domain.PullRequest `json:"pr"`
+	}
+	if expected == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out), "decode response")
+	}
+	return out.PR
+}
+
+func TestPullRequestLifecycle_DraftMarkReady(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_lifecycle_draft_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_lifecycle_draft_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_lifecycle_draft_u2", "username": "TestBob", "is_active": true},
+			{"user_id": "test_lifecycle_draft_u3", "username": "TestCharlie", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	pr := map[string]interface{}{
+		"pull_request_id":   "test_lifecycle_draft",
+		"pull_request_name": "Draft PR",
+		"author_id":         "test_lifecycle_draft_u1",
+		"draft":             true,
+	}
+
+	respCreate := helpers.PostJSON(t, "/pullRequest/create", pr, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respCreate.Body)
+	helpers.RequireStatusCode(t, respCreate, http.StatusCreated)
+
+	var created struct {
+		PR domain.PullRequest `json:"pr"`
+	}
+	require.NoError(t, json.NewDecoder(respCreate.Body).Decode(&created), "decode response")
+	assert.Equal(t, domain.DRAFT, created.PR.Status)
+	assert.Empty(t, created.PR.AssignedReviewers)
+
+	respAddReviewer := reviewerRequest(t, "/pullRequest/addReviewer", "test_lifecycle_draft", "test_lifecycle_draft_u2")
+	_ = respAddReviewer.Body.Close()
+	helpers.RequireStatusCode(t, respAddReviewer, http.StatusConflict)
+
+	changeStatus(t, "/pullRequest/merge", "test_lifecycle_draft", http.StatusConflict)
+
+	ready := changeStatus(t, "/pullRequest/markReady", "test_lifecycle_draft", http.StatusOK)
+	assert.Equal(t, domain.OPEN, ready.Status)
+	assert.ElementsMatch(t, []string{"test_lifecycle_draft_u2", "test_lifecycle_draft_u3"}, ready.AssignedReviewers)
+	assert.False(t, ready.NeedMoreReviewers)
+}
+
+func TestPullRequestLifecycle_CloseAndReopen(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name":        "test_lifecycle_close_team",
+		"max_open_reviews": 1,
+		"members": []map[string]interface{}{
+			{"user_id": "test_lifecycle_close_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_lifecycle_close_u2", "username": "TestBob", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	first := helpers.CreatePullRequest(t, "test_lifecycle_close_1", "test_lifecycle_close_u1")
+	require.Equal(t, []string{"test_lifecycle_close_u2"}, first.AssignedReviewers)
+
+	closed := changeStatus(t, "/pullRequest/close", "test_lifecycle_close_1", http.StatusOK)
+	assert.Equal(t, domain.CLOSED, closed.Status)
+	assert.Equal(t, []string{"test_lifecycle_close_u2"}, closed.AssignedReviewers, "reviewers are kept on close")
+	assert.False(t, closed.ClosedAt.IsZero())
+
+	var stats []domain.PullRequestStats
+	helpers.GetStats(t, "/stats/users?team_name=test_lifecycle_close_team", &stats)
+	for _, st := range stats {
+		assert.Zero(t, st.CurrentLoad, "closed PR does not count towards the load of %s", st.UserID)
+		assert.Zero(t, st.AssignedPRCount, "closed PR does not count towards the reviews of %s", st.UserID)
+	}
+
+	// the closed PR does not count towards the limit, so the next PR can take the reviewer
+	second := helpers.CreatePullRequest(t, "test_lifecycle_close_2", "test_lifecycle_close_u1")
+	assert.Equal(t, []string{"test_lifecycle_close_u2"}, second.AssignedReviewers)
+
+	respReassign := helpers.PatchJSON(t, "/pullRequest/reassign", map[string]interface{}{
+		"pull_request_id": "test_lifecycle_close_1",
+		"old_user_id":     "test_lifecycle_close_u2",
+	}, helpers.AdminToken)
+	_ = respReassign.Body.Close()
+	helpers.RequireStatusCode(t, respReassign, http.StatusConflict)
+
+	changeStatus(t, "/pullRequest/merge", "test_lifecycle_close_2", http.StatusOK)
+	changeStatus(t, "/pullRequest/close", "test_lifecycle_close_2", http.StatusConflict)
+
+	reopened := changeStatus(t, "/pullRequest/reopen", "test_lifecycle_close_1", http.StatusOK)
+	assert.Equal(t, domain.OPEN, reopened.Status)
+	assert.True(t, reopened.ClosedAt.IsZero())
+	assert.Equal(t, []string{"test_lifecycle_close_u2"}, reopened.AssignedReviewers)
+}
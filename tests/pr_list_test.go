@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type listPullRequestsResponse struct {
+	PullRequests []domain.PullRequest `json:"pull_requests"`
+	NextCursor   string               `json:"next_cursor"`
+}
+
+func listPullRequests(t *testing.T, query url.Values) listPullRequestsResponse {
+	t.Helper()
+
+	resp := helpers.GetJSON(t, "/pullRequest/list?"+query.Encode(), nil, helpers.UserToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+	helpers.RequireStatusCode(t, resp, http.StatusOK)
+
+	var out listPullRequestsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out), "decode response")
+	return out
+}
+
+func TestPullRequestGet(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_pr_get_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_pr_get_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_pr_get_u2", "username": "TestBob", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	created := helpers.CreatePullRequest(t, "test_pr_get", "test_pr_get_u1")
+
+	resp := helpers.GetJSON(t, "/pullRequest/get?pull_request_id=test_pr_get", nil, helpers.UserToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+	helpers.RequireStatusCode(t, resp, http.StatusOK)
+	assert.NotEmpty(t, resp.Header.Get("ETag"))
+
+	var out struct {
+		PR domain.PullRequest `json:"pr"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out), "decode response")
+	assert.Equal(t, created.PullRequestID, out.PR.PullRequestID)
+	assert.Equal(t, []string{"test_pr_get_u2"}, out.PR.AssignedReviewers)
+	assert.False(t, out.PR.CreatedAt.IsZero())
+
+	respMissing := helpers.GetJSON(t, "/pullRequest/get?pull_request_id=test_pr_get_missing", nil, helpers.UserToken)
+	_ = respMissing.Body.Close()
+	helpers.RequireStatusCode(t, respMissing, http.StatusNotFound)
+}
+
+func TestPullRequestList_FiltersAndPagination(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_pr_list_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_pr_list_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_pr_list_u2", "username": "TestBob", "is_active": true},
+			{"user_id": "test_pr_list_u3", "username": "TestCharlie", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	for _, prID := range []string{"test_pr_list_1", "test_pr_list_2", "test_pr_list_3"} {
+		helpers.CreatePullRequest(t, prID, "test_pr_list_u1")
+	}
+
+	respMerge := helpers.PatchJSON(t, "/pullRequest/merge", map[string]interface{}{"pull_request_id": "test_pr_list_2"}, helpers.AdminToken)
+	_ = respMerge.Body.Close()
+	helpers.RequireStatusCode(t, respMerge, http.StatusOK)
+
+	var ids []string
+	cursor := ""
+	for {
+		query := url.Values{"team_name": {"test_pr_list_team"}, "limit": {"2"}}
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		page := listPullRequests(t, query)
+		for _, pr := range page.PullRequests {
+			ids = append(ids, pr.PullRequestID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	assert.Equal(t, []string{"test_pr_list_1", "test_pr_list_2", "test_pr_list_3"}, ids)
+
+	desc := listPullRequests(t, url.Values{"author_id": {"test_pr_list_u1"}, "sort": {"pull_request_id"}, "order": {"desc"}})
+	require.Len(t, desc.PullRequests, 3)
+	assert.Equal(t, "test_pr_list_3", desc.PullRequests[0].PullRequestID)
+
+	merged := listPullRequests(t, url.Values{"team_name": {"test_pr_list_team"}, "status": {"MERGED"}})
+	require.Len(t, merged.PullRequests, 1)
+	assert.Equal(t, "test_pr_list_2", merged.PullRequests[0].PullRequestID)
+
+	reviewerID := merged.PullRequests[0].AssignedReviewers[0]
+	reviewed := listPullRequests(t, url.Values{"team_name": {"test_pr_list_team"}, "reviewer_id": {reviewerID}})
+	for _, pr := range reviewed.PullRequests {
+		assert.Contains(t, pr.AssignedReviewers, reviewerID)
+	}
+
+	respBad := helpers.GetJSON(t, "/pullRequest/list?cursor=not-a-cursor", nil, helpers.UserToken)
+	_ = respBad.Body.Close()
+	helpers.RequireStatusCode(t, respBad, http.StatusBadRequest)
+
+	first := listPullRequests(t, url.Values{"team_name": {"test_pr_list_team"}, "limit": {"1"}})
+	require.NotEmpty(t, first.NextCursor)
+	query := url.Values{"team_name": {"test_pr_list_team"}, "sort": {"pull_request_id"}, "cursor": {first.NextCursor}}
+	respMismatch := helpers.GetJSON(t, "/pullRequest/list?"+query.Encode(), nil, helpers.UserToken)
+	_ = respMismatch.Body.Close()
+	helpers.RequireStatusCode(t, respMismatch, http.StatusBadRequest)
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullRequestPreview_ExplainsRejections(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name":        "test_preview_team",
+		"max_open_reviews": 1,
+		"members": []map[string]interface{}{
+			{"user_id": "test_pv_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_pv_u2", "username": "TestBob", "is_active": true},
+			{"user_id": "test_pv_u3", "username": "TestCharlie", "is_active": false},
+			{"user_id": "test_pv_u4", "username": "TestSarah", "is_active": true},
+			{"user_id": "test_pv_u5", "username": "TestDave", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	absence := map[string]interface{}{
+		"user_id": "test_pv_u4",
+		"from":    time.Now().Add(-time.Hour).Format(time.RFC3339),
+		"to":      time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+	}
+
+	respAbsence := helpers.PostJSON(t, "/users/addAbsence", absence, helpers.AdminToken)
+	_ = respAbsence.Body.Close()
+	helpers.RequireStatusCode(t, respAbsence, http.StatusCreated)
+
+	// test_pv_u2 and test_pv_u5 take the only review slot each.
+	first := helpers.CreatePullRequest(t, "test_pv_pr_1", "test_pv_u1")
+	require.ElementsMatch(t, []string{"test_pv_u2", "test_pv_u5"}, first.AssignedReviewers)
+
+	capacity := map[string]interface{}{
+		"user_id":          "test_pv_u5",
+		"max_open_reviews": 5,
+	}
+
+	respCapacity := helpers.PatchJSON(t, "/users/setMaxOpenReviews", capacity, helpers.AdminToken)
+	_ = respCapacity.Body.Close()
+	helpers.RequireStatusCode(t, respCapacity, http.StatusOK)
+
+	respPreview := helpers.PostJSON(t, "/pullRequest/preview", map[string]interface{}{"author_id": "test_pv_u1"}, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respPreview.Body)
+	helpers.RequireStatusCode(t, respPreview, http.StatusOK)
+
+	var out struct {
+		Preview domain.AssignmentPreview `json:"preview"`
+	}
+	require.NoError(t, json.NewDecoder(respPreview.Body).Decode(&out), "decode response")
+
+	assert.Equal(t, "test_preview_team", out.Preview.TeamName)
+	assert.Equal(t, 2, out.Preview.RequiredReviewers)
+	assert.Equal(t, []string{"test_pv_u5"}, out.Preview.Reviewers)
+	assert.True(t, out.Preview.NeedMoreReviewers)
+
+	reasons := make(map[string]domain.RejectionReason)
+	for _, rejected := range out.Preview.Rejected {
+		reasons[rejected.UserID] = rejected.Reason
+	}
+	assert.Equal(t, domain.REJECTED_AUTHOR, reasons["test_pv_u1"])
+	assert.Equal(t, domain.REJECTED_AT_CAPACITY, reasons["test_pv_u2"])
+	assert.Equal(t, domain.REJECTED_INACTIVE, reasons["test_pv_u3"])
+	assert.Equal(t, domain.REJECTED_UNAVAILABLE, reasons["test_pv_u4"])
+
+	respStats := helpers.GetJSON(t, "/stats/users", nil, helpers.UserToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respStats.Body)
+	helpers.RequireStatusCode(t, respStats, http.StatusOK)
+
+	var stats struct {
+		Stats []domain.PullRequestStats `json:"stats"`
+	}
+	require.NoError(t, json.NewDecoder(respStats.Body).Decode(&stats), "decode response")
+
+	for _, s := range stats.Stats {
+		if s.UserID == "test_pv_u5" {
+			assert.Equal(t, 1, s.CurrentLoad, "preview must not assign reviewers")
+		}
+	}
+}
+
+func TestPullRequestPreview_AuthorNotFound(t *testing.T) {
+	respPreview := helpers.PostJSON(t, "/pullRequest/preview", map[string]interface{}{"author_id": "non_existent_user"}, helpers.AdminToken)
+	_ = respPreview.Body.Close()
+	helpers.RequireStatusCode(t, respPreview, http.StatusNotFound)
+}
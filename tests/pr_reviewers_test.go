@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reviewerRequest(t *testing.T, path, prID, userID string) *http.Response {
+	t.Helper()
+
+	body := map[string]interface{}{
+		"pull_request_id": prID,
+		"user_id":         userID,
+	}
+	return helpers.PostJSON(t, path, body, helpers.AdminToken)
+}
+
+func TestPullRequestReviewers_AddAndRemove(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name":          "test_manual_team",
+		"required_reviewers": 1,
+		"members": []map[string]interface{}{
+			{"user_id": "test_man_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_man_u2", "username": "TestBob", "is_active": true},
+			{"user_id": "test_man_u3", "username": "TestCharlie", "is_active": true},
+			{"user_id": "test_man_u4", "username": "TestSarah", "is_active": false},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	pr := helpers.CreatePullRequest(t, "test_man_pr", "test_man_u1")
+	require.Len(t, pr.AssignedReviewers, 1)
+	assigned := pr.AssignedReviewers[0]
+
+	respLimit := reviewerRequest(t, "/pullRequest/addReviewer", "test_man_pr", "test_man_u3")
+	_ = respLimit.Body.Close()
+	helpers.RequireStatusCode(t, respLimit, http.StatusConflict)
+
+	respRemove := reviewerRequest(t, "/pullRequest/removeReviewer", "test_man_pr", assigned)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respRemove.Body)
+	helpers.RequireStatusCode(t, respRemove, http.StatusOK)
+
+	var out struct {
+		PR domain.PullRequest `json:"pr"`
+	}
+	require.NoError(t, json.NewDecoder(respRemove.Body).Decode(&out), "decode response")
+	assert.Empty(t, out.PR.AssignedReviewers)
+	assert.True(t, out.PR.NeedMoreReviewers, "need_more_reviewers should be set after removal")
+
+	respAuthor := reviewerRequest(t, "/pullRequest/addReviewer", "test_man_pr", "test_man_u1")
+	_ = respAuthor.Body.Close()
+	helpers.RequireStatusCode(t, respAuthor, http.StatusConflict)
+
+	respInactive := reviewerRequest(t, "/pullRequest/addReviewer", "test_man_pr", "test_man_u4")
+	_ = respInactive.Body.Close()
+	helpers.RequireStatusCode(t, respInactive, http.StatusConflict)
+
+	respAddReviewer := reviewerRequest(t, "/pullRequest/addReviewer", "test_man_pr", "test_man_u3")
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respAddReviewer.Body)
+	helpers.RequireStatusCode(t, respAddReviewer, http.StatusOK)
+
+	require.NoError(t, json.NewDecoder(respAddReviewer.Body).Decode(&out), "decode response")
+	assert.Equal(t, []string{"test_man_u3"}, out.PR.AssignedReviewers)
+	assert.False(t, out.PR.NeedMoreReviewers, "need_more_reviewers should be cleared after adding")
+
+	respNotAssigned := reviewerRequest(t, "/pullRequest/removeReviewer", "test_man_pr", "test_man_u2")
+	_ = respNotAssigned.Body.Close()
+	helpers.RequireStatusCode(t, respNotAssigned, http.StatusConflict)
+}
+
+func TestPullRequestReviewers_ReassignToChosenUser(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_chosen_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_ch_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_ch_u2", "username": "TestBob", "is_active": true},
+			{"user_id": "test_ch_u3", "username": "TestCharlie", "is_active": true},
+			{"user_id": "test_ch_u4", "username": "TestSarah", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	pr := helpers.CreatePullRequest(t, "test_ch_pr", "test_ch_u1")
+	require.Len(t, pr.AssignedReviewers, 2)
+
+	var idle string
+	for _, id := range []string{"test_ch_u2", "test_ch_u3", "test_ch_u4"} {
+		if !slices.Contains(pr.AssignedReviewers, id) {
+			idle = id
+		}
+	}
+
+	respAuthor := helpers.PatchJSON(t, "/pullRequest/reassign", map[string]interface{}{
+		"pull_request_id": "test_ch_pr",
+		"old_user_id":     pr.AssignedReviewers[0],
+		"new_user_id":     "test_ch_u1",
+	}, helpers.AdminToken)
+	_ = respAuthor.Body.Close()
+	helpers.RequireStatusCode(t, respAuthor, http.StatusConflict)
+
+	respAssigned := helpers.PatchJSON(t, "/pullRequest/reassign", map[string]interface{}{
+		"pull_request_id": "test_ch_pr",
+		"old_user_id":     pr.AssignedReviewers[0],
+		"new_user_id":     pr.AssignedReviewers[1],
+	}, helpers.AdminToken)
+	_ = respAssigned.Body.Close()
+	helpers.RequireStatusCode(t, respAssigned, http.StatusConflict)
+
+	resp := helpers.PatchJSON(t, "/pullRequest/reassign", map[string]interface{}{
+		"pull_request_id": "test_ch_pr",
+		"old_user_id":     pr.AssignedReviewers[0],
+		"new_user_id":     idle,
+	}, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+	helpers.RequireStatusCode(t, resp, http.StatusOK)
+
+	var out struct {
+		PR         domain.PullRequest `json:"pr"`
+		ReplacedBy string             `json:"replaced_by"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out), "decode response")
+	assert.Equal(t, idle, out.ReplacedBy)
+	assert.ElementsMatch(t, []string{idle, pr.AssignedReviewers[1]}, out.PR.AssignedReviewers)
+}
+
+func TestPullRequestReviewers_MergedIsLocked(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_locked_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_lock_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_lock_u2", "username": "TestBob", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	helpers.CreatePullRequest(t, "test_lock_pr", "test_lock_u1")
+
+	respMerge := helpers.PatchJSON(t, "/pullRequest/merge", map[string]interface{}{"pull_request_id": "test_lock_pr"}, helpers.AdminToken)
+	_ = respMerge.Body.Close()
+	helpers.RequireStatusCode(t, respMerge, http.StatusOK)
+
+	respRemove := reviewerRequest(t, "/pullRequest/removeReviewer", "test_lock_pr", "test_lock_u2")
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respRemove.Body)
+	helpers.RequireStatusCode(t, respRemove, http.StatusConflict)
+
+	var errorResp struct {
+		Error struct {
+			Code domain.ErrorCode `json:"code"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.NewDecoder(respRemove.Body).Decode(&errorResp), "decode error response")
+	assert.Equal(t, domain.PR_MERGED, errorResp.Error.Code)
+}
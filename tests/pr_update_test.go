@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullRequestUpdate_MetadataAndAuthor(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name":          "test_pr_update_team",
+		"required_reviewers": 1,
+		"members": []map[string]interface{}{
+			{"user_id": "test_pr_update_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_pr_update_u2", "username": "TestBob", "is_active": true},
+			{"user_id": "test_pr_update_u3", "username": "TestCharlie", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	pr := map[string]interface{}{
+		"pull_request_id":   "test_pr_update",
+		"pull_request_name": "Update PR",
+		"author_id":         "test_pr_update_u1",
+		"repository":        "service",
+		"source_branch":     "feature",
+	}
+
+	respCreate := helpers.PostJSON(t, "/pullRequest/create", pr, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respCreate.Body)
+	helpers.RequireStatusCode(t, respCreate, http.StatusCreated)
+
+	var created struct {
+		PR domain.PullRequest `json:"pr"`
+	}
+	require.NoError(t, json.NewDecoder(respCreate.Body).Decode(&created), "decode response")
+	assert.Equal(t, "service", created.PR.Repository)
+	require.Len(t, created.PR.AssignedReviewers, 1)
+	reviewer := created.PR.AssignedReviewers[0]
+
+	update := map[string]interface{}{
+		"pull_request_id":   "test_pr_update",
+		"pull_request_name": "Renamed PR",
+		"author_id":         reviewer,
+		"target_branch":     "main",
+	}
+
+	respUpdate := helpers.PatchJSON(t, "/pullRequest/update", update, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respUpdate.Body)
+	helpers.RequireStatusCode(t, respUpdate, http.StatusOK)
+
+	var updated struct {
+		PR domain.PullRequest `json:"pr"`
+	}
+	require.NoError(t, json.NewDecoder(respUpdate.Body).Decode(&updated), "decode response")
+	assert.Equal(t, "Renamed PR", updated.PR.PullRequestName)
+	assert.Equal(t, reviewer, updated.PR.AuthorID)
+	assert.Equal(t, "service", updated.PR.Repository)
+	assert.Equal(t, "feature", updated.PR.SourceBranch)
+	assert.Equal(t, "main", updated.PR.TargetBranch)
+	require.Len(t, updated.PR.AssignedReviewers, 1)
+	assert.NotEqual(t, reviewer, updated.PR.AssignedReviewers[0], "new author must not stay a reviewer")
+
+	respMissing := helpers.PatchJSON(t, "/pullRequest/update", map[string]interface{}{
+		"pull_request_id": "test_pr_update",
+		"author_id":       "test_pr_update_missing",
+	}, helpers.AdminToken)
+	_ = respMissing.Body.Close()
+	helpers.RequireStatusCode(t, respMissing, http.StatusNotFound)
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsTeams(t *testing.T) {
+	helpers.AddTeam(t, "test_stats_team", "test_stats_a", "test_stats_b", "test_stats_c")
+
+	helpers.CreatePullRequest(t, "test_stats_pr1", "test_stats_a")
+	helpers.CreatePullRequest(t, "test_stats_pr2", "test_stats_a")
+
+	respMerge := helpers.PatchJSON(t, "/pullRequest/merge", map[string]interface{}{"pull_request_id": "test_stats_pr1"}, helpers.AdminToken)
+	_ = respMerge.Body.Close()
+	helpers.RequireStatusCode(t, respMerge, http.StatusOK)
+
+	var teams []domain.TeamStats
+	helpers.GetStats(t, "/stats/teams?team=test_stats_team", &teams)
+	require.Len(t, teams, 1)
+	assert.Equal(t, "test_stats_team", teams[0].TeamName)
+	assert.Equal(t, 2, teams[0].AuthoredPRCount)
+	assert.Equal(t, 4, teams[0].ReviewCount)
+	assert.Equal(t, 2, teams[0].MergedReviewCount)
+	assert.InDelta(t, 2.0, teams[0].AvgReviewersPerPR, 0.001)
+	assert.InDelta(t, 0.0, teams[0].NeedMoreReviewersShare, 0.001)
+
+	var merged []domain.TeamStats
+	helpers.GetStats(t, "/stats/teams?team=test_stats_team&status=MERGED", &merged)
+	require.Len(t, merged, 1)
+	assert.Equal(t, 1, merged[0].AuthoredPRCount)
+	assert.Equal(t, 2, merged[0].ReviewCount)
+
+	future := url.QueryEscape(time.Now().Add(time.Hour).Format(time.RFC3339))
+	var empty []domain.TeamStats
+	helpers.GetStats(t, "/stats/teams?team=test_stats_team&from="+future, &empty)
+	require.Len(t, empty, 1)
+	assert.Equal(t, 0, empty[0].AuthoredPRCount)
+	assert.Equal(t, 0, empty[0].ReviewCount)
+
+	var users []domain.PullRequestStats
+	helpers.GetStats(t, "/stats/users?team=test_stats_team&status=OPEN", &users)
+	require.Len(t, users, 3)
+	for _, st := range users {
+		assert.Equal(t, "test_stats_team", st.TeamName)
+		assert.Equal(t, 0, st.Merged)
+	}
+
+	respBad := helpers.GetJSON(t, "/stats/teams?status=UNKNOWN", nil, helpers.UserToken)
+	_ = respBad.Body.Close()
+	helpers.RequireStatusCode(t, respBad, http.StatusBadRequest)
+}
+
+func TestStatsTeams_AttributionSurvivesMove(t *testing.T) {
+	helpers.AddTeam(t, "test_stats_move_from", "test_stats_move_a", "test_stats_move_b", "test_stats_move_c")
+	helpers.AddTeam(t, "test_stats_move_to", "test_stats_move_d")
+
+	helpers.CreatePullRequest(t, "test_stats_move_pr", "test_stats_move_a")
+	moveTeam(t, "/users/moveTeam", "test_stats_move_a", "test_stats_move_to")
+
+	var from []domain.TeamStats
+	helpers.GetStats(t, "/stats/teams?team=test_stats_move_from", &from)
+	require.Len(t, from, 1)
+	assert.Equal(t, 1, from[0].AuthoredPRCount)
+	assert.Equal(t, 2, from[0].ReviewCount)
+
+	var to []domain.TeamStats
+	helpers.GetStats(t, "/stats/teams?team=test_stats_move_to", &to)
+	require.Len(t, to, 1)
+	assert.Equal(t, 0, to[0].AuthoredPRCount)
+	assert.Equal(t, 0, to[0].ReviewCount)
+
+	var users []domain.PullRequestStats
+	helpers.GetStats(t, "/stats/users?team=test_stats_move_to", &users)
+	require.Len(t, users, 2)
+	for _, st := range users {
+		assert.Equal(t, 0, st.AssignedPRCount)
+	}
+}
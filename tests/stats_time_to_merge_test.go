@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsTimeToMerge(t *testing.T) {
+	helpers.AddTeam(t, "test_ttm_team", "test_ttm_a", "test_ttm_b", "test_ttm_c")
+
+	firstDay := time.Now().UTC().Format(time.DateOnly)
+	for _, prID := range []string{"test_ttm_pr1", "test_ttm_pr2"} {
+		helpers.CreatePullRequest(t, prID, "test_ttm_a")
+
+		respMerge := helpers.PatchJSON(t, "/pullRequest/merge", map[string]interface{}{"pull_request_id": prID}, helpers.AdminToken)
+		_ = respMerge.Body.Close()
+		helpers.RequireStatusCode(t, respMerge, http.StatusOK)
+	}
+	lastDay := time.Now().UTC().Format(time.DateOnly)
+	helpers.CreatePullRequest(t, "test_ttm_pr_open", "test_ttm_a")
+
+	var byTeam []domain.TimeToMergeStats
+	helpers.GetStats(t, "/stats/timeToMerge?team=test_ttm_team", &byTeam)
+	require.Len(t, byTeam, 1)
+	assert.Equal(t, "test_ttm_team", byTeam[0].Key)
+	assert.Equal(t, 2, byTeam[0].MergedCount)
+	assert.GreaterOrEqual(t, byTeam[0].P50, 0.0)
+	assert.LessOrEqual(t, byTeam[0].P50, byTeam[0].P90)
+	assert.LessOrEqual(t, byTeam[0].P90, byTeam[0].P99)
+
+	var byAuthor []domain.TimeToMergeStats
+	helpers.GetStats(t, "/stats/timeToMerge?group_by=author&team=test_ttm_team", &byAuthor)
+	require.Len(t, byAuthor, 1)
+	assert.Equal(t, "test_ttm_a", byAuthor[0].Key)
+
+	var byReviewer []domain.TimeToMergeStats
+	helpers.GetStats(t, "/stats/timeToMerge?group_by=reviewer&team=test_ttm_team", &byReviewer)
+	require.Len(t, byReviewer, 2)
+	for _, st := range byReviewer {
+		assert.Contains(t, []string{"test_ttm_b", "test_ttm_c"}, st.Key)
+		assert.Equal(t, 2, st.MergedCount)
+	}
+
+	var daily []domain.TimeToMergeDay
+	helpers.GetStats(t, "/stats/timeToMerge/daily?team=test_ttm_team", &daily)
+	// The merges may straddle UTC midnight and land on two days.
+	require.NotEmpty(t, daily)
+	require.LessOrEqual(t, len(daily), 2)
+	merged := 0
+	for _, day := range daily {
+		assert.Contains(t, []string{firstDay, lastDay}, day.Day)
+		merged += day.MergedCount
+	}
+	assert.Equal(t, 2, merged)
+
+	respBad := helpers.GetJSON(t, "/stats/timeToMerge?group_by=unknown", nil, helpers.UserToken)
+	_ = respBad.Body.Close()
+	helpers.RequireStatusCode(t, respBad, http.StatusBadRequest)
+
+	respStatus := helpers.GetJSON(t, "/stats/timeToMerge/daily?status=OPEN", nil, helpers.UserToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respStatus.Body)
+	helpers.RequireStatusCode(t, respStatus, http.StatusBadRequest)
+}
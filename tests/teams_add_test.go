@@ -67,3 +67,25 @@ func TestTeamAdd_AlreadyExists(t *testing.T) {
 	require.NoError(t, json.Unmarshal(helpers.ReadBody(t, resp2), &errorResp), "decode error response")
 	assert.Equal(t, domain.TEAM_EXISTS, errorResp.Error.Code, "error code should be TEAM_EXISTS")
 }
+
+func TestTeamAdd_RolledBackOnMemberFailure(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_rollback_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_rb_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_rb_\u0000u2", "username": "TestBob", "is_active": true},
+		},
+	}
+
+	resp := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = resp.Body.Close()
+	require.NotEqual(t, http.StatusCreated, resp.StatusCode, "member with NUL in id must not be stored")
+
+	respGet := helpers.GetJSON(t, "/team/get/test_rollback_team", nil, helpers.AdminToken)
+	_ = respGet.Body.Close()
+	helpers.RequireStatusCode(t, respGet, http.StatusNotFound)
+
+	respUser := helpers.GetJSON(t, "/users/get?user_id=test_rb_u1", nil, helpers.AdminToken)
+	_ = respUser.Body.Close()
+	helpers.RequireStatusCode(t, respUser, http.StatusNotFound)
+}
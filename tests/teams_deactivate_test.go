@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func deactivateMembers(t *testing.T, body map[string]interface{}) domain.TeamDeactivation {
+	t.Helper()
+
+	resp := helpers.PostJSON(t, "/team/deactivateMembers", body, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+	helpers.RequireStatusCode(t, resp, http.StatusOK)
+
+	var out struct {
+		Result domain.TeamDeactivation `json:"result"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out), "decode response")
+	return out.Result
+}
+
+func TestTeamDeactivateMembers_ReassignsOpenReviews(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_deact_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_deact_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_deact_u2", "username": "TestBob", "is_active": true},
+			{"user_id": "test_deact_u3", "username": "TestCharlie", "is_active": true},
+			{"user_id": "test_deact_u4", "username": "TestSarah", "is_active": true},
+			{"user_id": "test_deact_u5", "username": "TestDave", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	first := helpers.CreatePullRequest(t, "test_deact_pr_1", "test_deact_u1")
+	require.Len(t, first.AssignedReviewers, 2)
+	helpers.CreatePullRequest(t, "test_deact_pr_2", "test_deact_u1")
+
+	leaving := first.AssignedReviewers
+	result := deactivateMembers(t, map[string]interface{}{
+		"team_name": "test_deact_team",
+		"user_ids":  leaving,
+	})
+
+	assert.ElementsMatch(t, leaving, result.DeactivatedUsers)
+	require.NotEmpty(t, result.Reassignments)
+
+	for _, reassignment := range result.Reassignments {
+		assert.Subset(t, leaving, reassignment.RemovedReviewers)
+		for _, reviewer := range reassignment.AssignedReviewers {
+			assert.NotContains(t, leaving, reviewer, "deactivated user must not stay a reviewer")
+			assert.NotEqual(t, "test_deact_u1", reviewer, "author must not be assigned")
+		}
+	}
+
+	respGet := helpers.GetJSON(t, "/team/get/test_deact_team", nil, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respGet.Body)
+	helpers.RequireStatusCode(t, respGet, http.StatusOK)
+
+	var got domain.Team
+	require.NoError(t, json.NewDecoder(respGet.Body).Decode(&got), "decode response")
+	for _, member := range got.Members {
+		for _, userID := range leaving {
+			if member.UserID == userID {
+				assert.False(t, member.IsActive)
+			}
+		}
+	}
+}
+
+func TestTeamDeactivateMembers_UnknownMember(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_deact_unknown_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_deact_unk_u1", "username": "TestAlice", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	resp := helpers.PostJSON(t, "/team/deactivateMembers", map[string]interface{}{
+		"team_name": "test_deact_unknown_team",
+		"user_ids":  []string{"test_deact_unk_u1", "somebody_else"},
+	}, helpers.AdminToken)
+	_ = resp.Body.Close()
+	helpers.RequireStatusCode(t, resp, http.StatusNotFound)
+}
+
+func TestTeamDeactivateMembers_WholeTeam(t *testing.T) {
+	const size = 200
+
+	members := make([]map[string]interface{}, 0, size)
+	for i := 0; i < size; i++ {
+		members = append(members, map[string]interface{}{
+			"user_id":   fmt.Sprintf("test_deact_big_u%d", i),
+			"username":  fmt.Sprintf("User%d", i),
+			"is_active": true,
+		})
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", map[string]interface{}{"team_name": "test_deact_big_team", "members": members}, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	for i := 0; i < 20; i++ {
+		helpers.CreatePullRequest(t, fmt.Sprintf("test_deact_big_pr_%d", i), fmt.Sprintf("test_deact_big_u%d", i))
+	}
+
+	result := deactivateMembers(t, map[string]interface{}{"team_name": "test_deact_big_team"})
+
+	assert.Len(t, result.DeactivatedUsers, size)
+	assert.Len(t, result.Reassignments, 20)
+	for _, reassignment := range result.Reassignments {
+		assert.Empty(t, reassignment.AssignedReviewers)
+		assert.True(t, reassignment.NeedMoreReviewers)
+	}
+}
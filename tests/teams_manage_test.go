@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeRemoval(t *testing.T, resp *http.Response) domain.TeamMembersRemoval {
+	t.Helper()
+
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+	helpers.RequireStatusCode(t, resp, http.StatusOK)
+
+	var out struct {
+		Result domain.TeamMembersRemoval `json:"result"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out), "decode response")
+	return out.Result
+}
+
+func TestTeamList(t *testing.T) {
+	helpers.AddTeam(t, "test_list_team", "test_list_u1", "test_list_u2")
+
+	resp := helpers.GetJSON(t, "/team/list", nil, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+	helpers.RequireStatusCode(t, resp, http.StatusOK)
+
+	var out struct {
+		Teams []domain.TeamSummary `json:"teams"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out), "decode response")
+
+	var found *domain.TeamSummary
+	for i := range out.Teams {
+		if out.Teams[i].TeamName == "test_list_team" {
+			found = &out.Teams[i]
+		}
+	}
+	require.NotNil(t, found)
+	assert.Equal(t, 2, found.MemberCount)
+	assert.Equal(t, 2, found.ActiveMemberCount)
+}
+
+func TestTeamRename(t *testing.T) {
+	helpers.AddTeam(t, "test_rename_team", "test_rename_u1", "test_rename_u2")
+	helpers.AddTeam(t, "test_rename_taken", "test_rename_u3")
+
+	rename := map[string]interface{}{"team_name": "test_rename_team", "new_team_name": "test_rename_taken"}
+	respTaken := helpers.PatchJSON(t, "/team/rename", rename, helpers.AdminToken)
+	_ = respTaken.Body.Close()
+	helpers.RequireStatusCode(t, respTaken, http.StatusBadRequest)
+
+	rename["new_team_name"] = "test_renamed_team"
+	respRename := helpers.PatchJSON(t, "/team/rename", rename, helpers.AdminToken)
+	_ = respRename.Body.Close()
+	helpers.RequireStatusCode(t, respRename, http.StatusOK)
+
+	respOld := helpers.GetJSON(t, "/team/get/test_rename_team", nil, helpers.AdminToken)
+	_ = respOld.Body.Close()
+	helpers.RequireStatusCode(t, respOld, http.StatusNotFound)
+
+	respNew := helpers.GetJSON(t, "/team/get/test_renamed_team", nil, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respNew.Body)
+	helpers.RequireStatusCode(t, respNew, http.StatusOK)
+
+	var team domain.Team
+	require.NoError(t, json.NewDecoder(respNew.Body).Decode(&team), "decode response")
+	assert.Len(t, team.Members, 2)
+}
+
+func TestTeamRemoveMember(t *testing.T) {
+	helpers.AddTeam(t, "test_remove_team", "test_remove_u1", "test_remove_u2", "test_remove_u3")
+
+	reviewed := helpers.CreatePullRequest(t, "test_remove_pr_1", "test_remove_u1")
+	require.Contains(t, reviewed.AssignedReviewers, "test_remove_u2")
+	helpers.CreatePullRequest(t, "test_remove_pr_2", "test_remove_u2")
+
+	result := decodeRemoval(t, helpers.DeleteJSON(t, "/team/removeMember/test_remove_team/test_remove_u2", helpers.AdminToken))
+	assert.Equal(t, []string{"test_remove_u2"}, result.RemovedUsers)
+	assert.Equal(t, []string{"test_remove_pr_2"}, result.ClosedPullRequests)
+	require.Len(t, result.Reassignments, 1)
+	assert.Equal(t, "test_remove_pr_1", result.Reassignments[0].PullRequestID)
+	assert.NotContains(t, result.Reassignments[0].AssignedReviewers, "test_remove_u2")
+
+	respAgain := helpers.DeleteJSON(t, "/team/removeMember/test_remove_team/test_remove_u2", helpers.AdminToken)
+	_ = respAgain.Body.Close()
+	helpers.RequireStatusCode(t, respAgain, http.StatusNotFound)
+}
+
+func TestTeamDelete_MovesMembers(t *testing.T) {
+	helpers.AddTeam(t, "test_delete_team", "test_delete_u1", "test_delete_u2")
+	helpers.AddTeam(t, "test_delete_target", "test_delete_u3")
+
+	result := decodeRemoval(t, helpers.DeleteJSON(t, "/team/delete/test_delete_team?move_members_to=test_delete_target", helpers.AdminToken))
+	assert.ElementsMatch(t, []string{"test_delete_u1", "test_delete_u2"}, result.MovedUsers)
+	assert.Equal(t, "test_delete_target", result.MovedTo)
+
+	respDeleted := helpers.GetJSON(t, "/team/get/test_delete_team", nil, helpers.AdminToken)
+	_ = respDeleted.Body.Close()
+	helpers.RequireStatusCode(t, respDeleted, http.StatusNotFound)
+
+	respTarget := helpers.GetJSON(t, "/team/get/test_delete_target", nil, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respTarget.Body)
+	helpers.RequireStatusCode(t, respTarget, http.StatusOK)
+
+	var team domain.Team
+	require.NoError(t, json.NewDecoder(respTarget.Body).Decode(&team), "decode response")
+	assert.Len(t, team.Members, 3)
+}
+
+func TestTeamDelete_Crosswise(t *testing.T) {
+	helpers.AddTeam(t, "test_cross_a", "test_cross_a1", "test_cross_a2", "test_cross_a3")
+	helpers.AddTeam(t, "test_cross_b", "test_cross_b1", "test_cross_b2", "test_cross_b3")
+	helpers.CreatePullRequest(t, "test_cross_pr_a", "test_cross_a1")
+	helpers.CreatePullRequest(t, "test_cross_pr_b", "test_cross_b1")
+
+	var wg sync.WaitGroup
+	statuses := make(chan int, 2)
+	for _, path := range []string{
+		"/team/delete/test_cross_a?move_members_to=test_cross_b",
+		"/team/delete/test_cross_b?move_members_to=test_cross_a",
+	} {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+
+			resp := helpers.DeleteJSON(t, path, helpers.AdminToken)
+			_ = resp.Body.Close()
+			statuses <- resp.StatusCode
+		}(path)
+	}
+
+	wg.Wait()
+	close(statuses)
+
+	var codes []int
+	for status := range statuses {
+		codes = append(codes, status)
+	}
+	assert.ElementsMatch(t, []int{http.StatusOK, http.StatusNotFound}, codes, "one deletion wins, the other finds its target gone")
+}
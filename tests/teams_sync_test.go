@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func syncTeam(t *testing.T, path string, body interface{}) domain.TeamSync {
+	t.Helper()
+
+	resp := helpers.DoJSON(t, http.MethodPut, path, body, helpers.AdminToken, nil)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+	helpers.RequireStatusCode(t, resp, http.StatusOK)
+
+	var out struct {
+		Sync domain.TeamSync `json:"sync"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out), "decode response")
+	return out.Sync
+}
+
+func TestTeamSync_DryRunAndApply(t *testing.T) {
+	helpers.AddTeam(t, "test_sync_team", "test_sync_u1", "test_sync_u2", "test_sync_u3")
+	helpers.AddTeam(t, "test_sync_other", "test_sync_u4")
+
+	body := map[string]interface{}{
+		"team_name": "test_sync_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_sync_u1", "username": "test_sync_u1", "is_active": true},
+			{"user_id": "test_sync_u2", "username": "test_sync_u2", "is_active": false},
+			{"user_id": "test_sync_u4", "username": "test_sync_u4", "is_active": true},
+			{"user_id": "test_sync_u5", "username": "test_sync_u5", "is_active": true},
+		},
+	}
+
+	preview := syncTeam(t, "/team/sync?dry_run=true", body)
+	assert.True(t, preview.DryRun)
+	assert.False(t, preview.Created)
+	assert.Equal(t, []string{"test_sync_u5"}, preview.Added)
+	assert.Equal(t, []string{"test_sync_u3"}, preview.Removed)
+	assert.Equal(t, []domain.TeamMemberMove{{UserID: "test_sync_u4", FromTeam: "test_sync_other"}}, preview.Moved)
+	assert.Empty(t, preview.Activated)
+	assert.Equal(t, []string{"test_sync_u2"}, preview.Deactivated)
+
+	respGet := helpers.GetJSON(t, "/team/get/test_sync_team", nil, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respGet.Body)
+	helpers.RequireStatusCode(t, respGet, http.StatusOK)
+
+	var before domain.Team
+	require.NoError(t, json.NewDecoder(respGet.Body).Decode(&before), "decode response")
+	assert.Len(t, before.Members, 3, "dry run must not change the team")
+
+	applied := syncTeam(t, "/team/sync", body)
+	assert.False(t, applied.DryRun)
+	assert.Equal(t, preview.Added, applied.Added)
+	assert.Equal(t, preview.Removed, applied.Removed)
+	assert.Equal(t, preview.Moved, applied.Moved)
+	assert.Equal(t, preview.Deactivated, applied.Deactivated)
+
+	respAfter := helpers.GetJSON(t, "/team/get/test_sync_team", nil, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respAfter.Body)
+	helpers.RequireStatusCode(t, respAfter, http.StatusOK)
+
+	var after domain.Team
+	require.NoError(t, json.NewDecoder(respAfter.Body).Decode(&after), "decode response")
+
+	active := make(map[string]bool)
+	for _, member := range after.Members {
+		active[member.UserID] = member.IsActive
+	}
+	assert.Equal(t, map[string]bool{
+		"test_sync_u1": true,
+		"test_sync_u2": false,
+		"test_sync_u4": true,
+		"test_sync_u5": true,
+	}, active)
+
+	again := syncTeam(t, "/team/sync", body)
+	assert.Empty(t, again.Added)
+	assert.Empty(t, again.Removed)
+	assert.Empty(t, again.Moved)
+	assert.Empty(t, again.Deactivated)
+}
+
+func TestTeamSync_CreatesTeam(t *testing.T) {
+	body := map[string]interface{}{
+		"team_name": "test_sync_new_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_sync_new_u1", "username": "TestAlice", "is_active": true},
+		},
+	}
+
+	result := syncTeam(t, "/team/sync", body)
+	assert.True(t, result.Created)
+	assert.Equal(t, []string{"test_sync_new_u1"}, result.Added)
+
+	respGet := helpers.GetJSON(t, "/team/get/test_sync_new_team", nil, helpers.AdminToken)
+	_ = respGet.Body.Close()
+	helpers.RequireStatusCode(t, respGet, http.StatusOK)
+}
+
+func TestTeamSync_ConcurrentCreate(t *testing.T) {
+	body := map[string]interface{}{
+		"team_name": "test_sync_race_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_sync_race_u1", "username": "test_sync_race_u1", "is_active": true},
+			{"user_id": "test_sync_race_u2", "username": "test_sync_race_u2", "is_active": true},
+		},
+	}
+
+	type syncResult struct {
+		status  int
+		created bool
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan syncResult, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			resp := helpers.DoJSON(t, http.MethodPut, "/team/sync", body, helpers.AdminToken, nil)
+			defer func(Body io.ReadCloser) {
+				_ = Body.Close()
+			}(resp.Body)
+
+			var out struct {
+				Sync domain.TeamSync `json:"sync"`
+			}
+			_ = json.NewDecoder(resp.Body).Decode(&out)
+			results <- syncResult{status: resp.StatusCode, created: out.Sync.Created}
+		}()
+	}
+
+	wg.Wait()
+	close(results)
+
+	created := 0
+	for result := range results {
+		assert.Equal(t, http.StatusOK, result.status, "concurrent syncs of a new team must not fail")
+		if result.created {
+			created++
+		}
+	}
+	assert.Equal(t, 1, created, "the team is created once")
+}
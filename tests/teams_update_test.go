@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamUpdate_RequiredReviewers(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name":          "test_required_team",
+		"required_reviewers": 3,
+		"members": []map[string]interface{}{
+			{"user_id": "test_req_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_req_u2", "username": "TestBob", "is_active": true},
+			{"user_id": "test_req_u3", "username": "TestCharlie", "is_active": true},
+			{"user_id": "test_req_u4", "username": "TestSarah", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	var out struct {
+		PR domain.PullRequest `json:"pr"`
+	}
+
+	pr := map[string]interface{}{
+		"pull_request_id":   "test_req_pr_1",
+		"pull_request_name": "Three reviewers",
+		"author_id":         "test_req_u1",
+	}
+
+	respCreate := helpers.PostJSON(t, "/pullRequest/create", pr, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respCreate.Body)
+	helpers.RequireStatusCode(t, respCreate, http.StatusCreated)
+	require.NoError(t, json.NewDecoder(respCreate.Body).Decode(&out), "decode response")
+
+	assert.Len(t, out.PR.AssignedReviewers, 3, "should assign required number of reviewers")
+	assert.False(t, out.PR.NeedMoreReviewers)
+
+	update := map[string]interface{}{
+		"team_name":          "test_required_team",
+		"required_reviewers": 1,
+	}
+
+	respUpdate := helpers.PatchJSON(t, "/team/update", update, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respUpdate.Body)
+	helpers.RequireStatusCode(t, respUpdate, http.StatusOK)
+
+	var updated struct {
+		Team domain.Team `json:"team"`
+	}
+	require.NoError(t, json.NewDecoder(respUpdate.Body).Decode(&updated), "decode response")
+	assert.Equal(t, 1, updated.Team.RequiredReviewers)
+	assert.Len(t, updated.Team.Members, 4)
+
+	pr["pull_request_id"] = "test_req_pr_2"
+	respCreate2 := helpers.PostJSON(t, "/pullRequest/create", pr, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respCreate2.Body)
+	helpers.RequireStatusCode(t, respCreate2, http.StatusCreated)
+	require.NoError(t, json.NewDecoder(respCreate2.Body).Decode(&out), "decode response")
+
+	assert.Len(t, out.PR.AssignedReviewers, 1, "should follow updated team setting")
+	assert.False(t, out.PR.NeedMoreReviewers)
+}
+
+func TestTeamUpdate_NeedMoreReviewers(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_need_more_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_need_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_need_u2", "username": "TestBob", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	pr := map[string]interface{}{
+		"pull_request_id":   "test_need_pr_1",
+		"pull_request_name": "Small team",
+		"author_id":         "test_need_u1",
+	}
+
+	respCreate := helpers.PostJSON(t, "/pullRequest/create", pr, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respCreate.Body)
+	helpers.RequireStatusCode(t, respCreate, http.StatusCreated)
+
+	var out struct {
+		PR domain.PullRequest `json:"pr"`
+	}
+	require.NoError(t, json.NewDecoder(respCreate.Body).Decode(&out), "decode response")
+
+	assert.Equal(t, []string{"test_need_u2"}, out.PR.AssignedReviewers)
+	assert.True(t, out.PR.NeedMoreReviewers, "one reviewer out of default two")
+
+	needMore := func(requiredReviewers int) bool {
+		update := map[string]interface{}{
+			"team_name":          "test_need_more_team",
+			"required_reviewers": requiredReviewers,
+		}
+		respUpdate := helpers.PatchJSON(t, "/team/update", update, helpers.AdminToken)
+		_ = respUpdate.Body.Close()
+		helpers.RequireStatusCode(t, respUpdate, http.StatusOK)
+
+		respGet := helpers.GetJSON(t, "/pullRequest/get?pull_request_id=test_need_pr_1", nil, helpers.UserToken)
+		defer func(Body io.ReadCloser) {
+			_ = Body.Close()
+		}(respGet.Body)
+		helpers.RequireStatusCode(t, respGet, http.StatusOK)
+
+		var got struct {
+			PR domain.PullRequest `json:"pr"`
+		}
+		require.NoError(t, json.NewDecoder(respGet.Body).Decode(&got), "decode response")
+		return got.PR.NeedMoreReviewers
+	}
+
+	assert.False(t, needMore(1), "flag is recomputed when required_reviewers goes down")
+	assert.True(t, needMore(2), "flag is recomputed when required_reviewers goes up")
+}
+
+func TestTeamUpdate_NotFound(t *testing.T) {
+	update := map[string]interface{}{
+		"team_name":          "test_missing_team",
+		"required_reviewers": 1,
+	}
+
+	resp := helpers.PatchJSON(t, "/team/update", update, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	helpers.RequireStatusCode(t, resp, http.StatusNotFound)
+}
+
+func TestTeamUpdate_Unauthorized(t *testing.T) {
+	update := map[string]interface{}{
+		"team_name":          "test_required_team",
+		"required_reviewers": 1,
+	}
+
+	resp := helpers.PatchJSON(t, "/team/update", update, helpers.UserToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
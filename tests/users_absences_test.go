@@ -0,0 +1,168 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserAbsence_SkippedByAssignment(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_absence_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_abs_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_abs_u2", "username": "TestBob", "is_active": true},
+			{"user_id": "test_abs_u3", "username": "TestCharlie", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	absence := map[string]interface{}{
+		"user_id": "test_abs_u2",
+		"from":    time.Now().Add(-time.Hour).Format(time.RFC3339),
+		"to":      time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+		"reason":  "vacation",
+	}
+
+	respAbsence := helpers.PostJSON(t, "/users/addAbsence", absence, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respAbsence.Body)
+	helpers.RequireStatusCode(t, respAbsence, http.StatusCreated)
+
+	var created struct {
+		Absence domain.Absence `json:"absence"`
+	}
+	require.NoError(t, json.NewDecoder(respAbsence.Body).Decode(&created), "decode response")
+	require.NotZero(t, created.Absence.AbsenceID)
+
+	pr := map[string]interface{}{
+		"pull_request_id":   "test_abs_pr_1",
+		"pull_request_name": "While Bob is away",
+		"author_id":         "test_abs_u1",
+	}
+
+	respCreate := helpers.PostJSON(t, "/pullRequest/create", pr, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respCreate.Body)
+	helpers.RequireStatusCode(t, respCreate, http.StatusCreated)
+
+	var out struct {
+		PR domain.PullRequest `json:"pr"`
+	}
+	require.NoError(t, json.NewDecoder(respCreate.Body).Decode(&out), "decode response")
+	assert.Equal(t, []string{"test_abs_u3"}, out.PR.AssignedReviewers, "absent user should be skipped")
+	assert.True(t, out.PR.NeedMoreReviewers)
+
+	respTeam := helpers.GetJSON(t, "/team/get/test_absence_team", nil, helpers.UserToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respTeam.Body)
+	helpers.RequireStatusCode(t, respTeam, http.StatusOK)
+
+	var teamOut domain.Team
+	require.NoError(t, json.NewDecoder(respTeam.Body).Decode(&teamOut), "decode response")
+	for _, member := range teamOut.Members {
+		if member.UserID == "test_abs_u2" {
+			require.Len(t, member.Absences, 1)
+			assert.Equal(t, "vacation", member.Absences[0].Reason)
+			assert.True(t, member.IsActive, "absence should not touch is_active")
+		} else {
+			assert.Empty(t, member.Absences)
+		}
+	}
+}
+
+func TestUserAbsence_UpdateAndDelete(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_absence_crud_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_abs_crud_u1", "username": "TestAlice", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	from := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	absence := map[string]interface{}{
+		"user_id": "test_abs_crud_u1",
+		"from":    from.Format(time.RFC3339),
+		"to":      from.Add(72 * time.Hour).Format(time.RFC3339),
+		"reason":  "conference",
+	}
+
+	respAbsence := helpers.PostJSON(t, "/users/addAbsence", absence, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respAbsence.Body)
+	helpers.RequireStatusCode(t, respAbsence, http.StatusCreated)
+
+	var created struct {
+		Absence domain.Absence `json:"absence"`
+	}
+	require.NoError(t, json.NewDecoder(respAbsence.Body).Decode(&created), "decode response")
+
+	update := map[string]interface{}{
+		"absence_id": created.Absence.AbsenceID,
+		"from":       from.Format(time.RFC3339),
+		"to":         from.Add(24 * time.Hour).Format(time.RFC3339),
+		"reason":     "sick leave",
+	}
+
+	respUpdate := helpers.PatchJSON(t, "/users/updateAbsence", update, helpers.AdminToken)
+	_ = respUpdate.Body.Close()
+	helpers.RequireStatusCode(t, respUpdate, http.StatusOK)
+
+	respList := helpers.GetJSON(t, "/users/getAbsences/test_abs_crud_u1", nil, helpers.UserToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respList.Body)
+	helpers.RequireStatusCode(t, respList, http.StatusOK)
+
+	var list struct {
+		Absences []domain.Absence `json:"absences"`
+	}
+	require.NoError(t, json.NewDecoder(respList.Body).Decode(&list), "decode response")
+	require.Len(t, list.Absences, 1)
+	assert.Equal(t, "sick leave", list.Absences[0].Reason)
+	assert.True(t, from.Add(24*time.Hour).Equal(list.Absences[0].To))
+
+	path := fmt.Sprintf("/users/deleteAbsence/%d", created.Absence.AbsenceID)
+
+	respDelete := helpers.DeleteJSON(t, path, helpers.AdminToken)
+	_ = respDelete.Body.Close()
+	helpers.RequireStatusCode(t, respDelete, http.StatusNoContent)
+
+	respDeleteAgain := helpers.DeleteJSON(t, path, helpers.AdminToken)
+	_ = respDeleteAgain.Body.Close()
+	helpers.RequireStatusCode(t, respDeleteAgain, http.StatusNotFound)
+}
+
+func TestUserAbsence_InvalidPeriod(t *testing.T) {
+	absence := map[string]interface{}{
+		"user_id": "test_abs_crud_u1",
+		"from":    time.Now().Add(time.Hour).Format(time.RFC3339),
+		"to":      time.Now().Format(time.RFC3339),
+	}
+
+	resp := helpers.PostJSON(t, "/users/addAbsence", absence, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	helpers.RequireStatusCode(t, resp, http.StatusBadRequest)
+}
@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserCapacity_RespectedByAssignment(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name":        "test_capacity_team",
+		"max_open_reviews": 1,
+		"members": []map[string]interface{}{
+			{"user_id": "test_cap_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_cap_u2", "username": "TestBob", "is_active": true},
+			{"user_id": "test_cap_u3", "username": "TestCharlie", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	first := helpers.CreatePullRequest(t, "test_cap_pr_1", "test_cap_u1")
+	assert.ElementsMatch(t, []string{"test_cap_u2", "test_cap_u3"}, first.AssignedReviewers)
+
+	second := helpers.CreatePullRequest(t, "test_cap_pr_2", "test_cap_u1")
+	assert.Empty(t, second.AssignedReviewers, "everyone is at capacity")
+	assert.True(t, second.NeedMoreReviewers)
+
+	capacity := map[string]interface{}{
+		"user_id":          "test_cap_u2",
+		"max_open_reviews": 5,
+	}
+
+	respCapacity := helpers.PatchJSON(t, "/users/setMaxOpenReviews", capacity, helpers.AdminToken)
+	_ = respCapacity.Body.Close()
+	helpers.RequireStatusCode(t, respCapacity, http.StatusOK)
+
+	third := helpers.CreatePullRequest(t, "test_cap_pr_3", "test_cap_u1")
+	assert.Equal(t, []string{"test_cap_u2"}, third.AssignedReviewers)
+
+	respStats := helpers.GetJSON(t, "/stats/users", nil, helpers.UserToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respStats.Body)
+	helpers.RequireStatusCode(t, respStats, http.StatusOK)
+
+	var stats struct {
+		Stats []domain.PullRequestStats `json:"stats"`
+	}
+	require.NoError(t, json.NewDecoder(respStats.Body).Decode(&stats), "decode response")
+
+	byUser := make(map[string]domain.PullRequestStats)
+	for _, st := range stats.Stats {
+		byUser[st.UserID] = st
+	}
+
+	require.NotNil(t, byUser["test_cap_u2"].Capacity)
+	assert.Equal(t, 5, *byUser["test_cap_u2"].Capacity)
+	assert.Equal(t, 2, byUser["test_cap_u2"].CurrentLoad)
+	require.NotNil(t, byUser["test_cap_u3"].Capacity)
+	assert.Equal(t, 1, *byUser["test_cap_u3"].Capacity)
+	assert.Equal(t, 1, byUser["test_cap_u3"].CurrentLoad)
+}
+
+func TestUserCapacity_Validation(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name":        "test_capacity_rule_team",
+		"max_open_reviews": 2,
+		"members": []map[string]interface{}{
+			{"user_id": "test_cap_rule_u1", "username": "TestAlice", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	for _, value := range []int{0, -1} {
+		respUser := helpers.PatchJSON(t, "/users/setMaxOpenReviews", map[string]interface{}{
+			"user_id":          "test_cap_rule_u1",
+			"max_open_reviews": value,
+		}, helpers.AdminToken)
+		_ = respUser.Body.Close()
+		helpers.RequireStatusCode(t, respUser, http.StatusBadRequest)
+
+		respTeam := helpers.PatchJSON(t, "/team/update", map[string]interface{}{
+			"team_name":        "test_capacity_rule_team",
+			"max_open_reviews": value,
+		}, helpers.AdminToken)
+		_ = respTeam.Body.Close()
+		helpers.RequireStatusCode(t, respTeam, http.StatusBadRequest)
+	}
+
+	respClear := helpers.PatchJSON(t, "/users/setMaxOpenReviews", map[string]interface{}{
+		"user_id":          "test_cap_rule_u1",
+		"max_open_reviews": nil,
+	}, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respClear.Body)
+	helpers.RequireStatusCode(t, respClear, http.StatusOK)
+
+	var user struct {
+		User domain.User `json:"user"`
+	}
+	require.NoError(t, json.NewDecoder(respClear.Body).Decode(&user), "decode response")
+	assert.Nil(t, user.User.MaxOpenReviews)
+
+	respUnchanged := helpers.PatchJSON(t, "/team/update", map[string]interface{}{
+		"team_name":          "test_capacity_rule_team",
+		"required_reviewers": 1,
+	}, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respUnchanged.Body)
+	helpers.RequireStatusCode(t, respUnchanged, http.StatusOK)
+
+	var unchanged struct {
+		Team domain.Team `json:"team"`
+	}
+	require.NoError(t, json.NewDecoder(respUnchanged.Body).Decode(&unchanged), "decode response")
+	require.NotNil(t, unchanged.Team.MaxOpenReviews, "omitted max_open_reviews keeps the limit")
+	assert.Equal(t, 2, *unchanged.Team.MaxOpenReviews)
+
+	respCleared := helpers.PatchJSON(t, "/team/update", map[string]interface{}{
+		"team_name":        "test_capacity_rule_team",
+		"max_open_reviews": nil,
+	}, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respCleared.Body)
+	helpers.RequireStatusCode(t, respCleared, http.StatusOK)
+
+	var cleared struct {
+		Team domain.Team `json:"team"`
+	}
+	require.NoError(t, json.NewDecoder(respCleared.Body).Decode(&cleared), "decode response")
+	assert.Nil(t, cleared.Team.MaxOpenReviews, "null clears the limit")
+}
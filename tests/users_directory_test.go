@@ -0,0 +1,200 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeUser(t *testing.T, resp *http.Response, expected int) domain.User {
+	t.Helper()
+
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+	helpers.RequireStatusCode(t, resp, expected)
+
+	var out struct {
+		User domain.User `json:"user"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out), "decode response")
+	return out.User
+}
+
+func TestUserCreateGetUpdate(t *testing.T) {
+	helpers.AddTeam(t, "test_dir_team", "test_dir_u1")
+
+	created := decodeUser(t, helpers.PostJSON(t, "/users/create", map[string]interface{}{
+		"user_id":   "test_dir_new",
+		"username":  "Newcomer",
+		"team_name": "test_dir_team",
+	}, helpers.AdminToken), http.StatusCreated)
+	assert.Equal(t, "test_dir_team", created.TeamName)
+	assert.True(t, created.IsActive)
+
+	respDup := helpers.PostJSON(t, "/users/create", map[string]interface{}{
+		"user_id":   "test_dir_new",
+		"username":  "Newcomer",
+		"team_name": "test_dir_team",
+	}, helpers.AdminToken)
+	_ = respDup.Body.Close()
+	helpers.RequireStatusCode(t, respDup, http.StatusConflict)
+
+	respNoTeam := helpers.PostJSON(t, "/users/create", map[string]interface{}{
+		"user_id":   "test_dir_orphan",
+		"username":  "Orphan",
+		"team_name": "test_dir_missing",
+	}, helpers.AdminToken)
+	_ = respNoTeam.Body.Close()
+	helpers.RequireStatusCode(t, respNoTeam, http.StatusNotFound)
+
+	updated := decodeUser(t, helpers.PatchJSON(t, "/users/update", map[string]interface{}{
+		"user_id":  "test_dir_new",
+		"username": "Renamed",
+	}, helpers.AdminToken), http.StatusOK)
+	assert.Equal(t, "Renamed", updated.Username)
+
+	got := decodeUser(t, helpers.GetJSON(t, "/users/get?user_id=test_dir_new", nil, helpers.UserToken), http.StatusOK)
+	assert.Equal(t, updated, got)
+}
+
+func TestUserList(t *testing.T) {
+	helpers.AddTeam(t, "test_dir_list", "test_dir_list_a", "test_dir_list_b", "test_dir_list_c")
+
+	respSet := helpers.PatchJSON(t, "/users/setIsActive", map[string]interface{}{"user_id": "test_dir_list_c", "is_active": false}, helpers.AdminToken)
+	_ = respSet.Body.Close()
+	helpers.RequireStatusCode(t, respSet, http.StatusOK)
+
+	list := func(path string) ([]domain.User, string) {
+		resp := helpers.GetJSON(t, path, nil, helpers.UserToken)
+		defer func(Body io.ReadCloser) {
+			_ = Body.Close()
+		}(resp.Body)
+		helpers.RequireStatusCode(t, resp, http.StatusOK)
+
+		var out struct {
+			Users      []domain.User `json:"users"`
+			NextCursor string        `json:"next_cursor"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out), "decode response")
+		return out.Users, out.NextCursor
+	}
+
+	page, cursor := list("/users/list?team_name=test_dir_list&is_active=true&limit=1")
+	require.Len(t, page, 1)
+	assert.Equal(t, "test_dir_list_a", page[0].UserID)
+	require.NotEmpty(t, cursor)
+
+	page, cursor = list("/users/list?team_name=test_dir_list&is_active=true&limit=1&cursor=" + cursor)
+	require.Len(t, page, 1)
+	assert.Equal(t, "test_dir_list_b", page[0].UserID)
+	assert.Empty(t, cursor)
+
+	page, _ = list("/users/list?name_prefix=TEST_DIR_LIST_")
+	assert.Len(t, page, 3)
+}
+
+func TestUserDelete(t *testing.T) {
+	helpers.AddTeam(t, "test_dir_del", "test_dir_del_a", "test_dir_del_b", "test_dir_del_c", "test_dir_del_d")
+
+	pr := helpers.CreatePullRequest(t, "test_dir_del_pr", "test_dir_del_a")
+	require.Len(t, pr.AssignedReviewers, 2)
+	reviewer := pr.AssignedReviewers[0]
+
+	respBusy := helpers.DeleteJSON(t, "/users/delete/"+reviewer, helpers.AdminToken)
+	_ = respBusy.Body.Close()
+	helpers.RequireStatusCode(t, respBusy, http.StatusConflict)
+
+	resp := helpers.DeleteJSON(t, "/users/delete/"+reviewer+"?force=true", helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+	helpers.RequireStatusCode(t, resp, http.StatusOK)
+
+	var out struct {
+		Result domain.UserDeletion `json:"result"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out), "decode response")
+	require.Len(t, out.Result.Reassignments, 1)
+	assert.Equal(t, []string{reviewer}, out.Result.Reassignments[0].RemovedReviewers)
+	assert.NotContains(t, out.Result.Reassignments[0].AssignedReviewers, reviewer)
+
+	respGet := helpers.GetJSON(t, "/users/get?user_id="+reviewer, nil, helpers.UserToken)
+	_ = respGet.Body.Close()
+	helpers.RequireStatusCode(t, respGet, http.StatusNotFound)
+
+	// A deleted user cannot be updated or reactivated.
+	updates := map[string]map[string]interface{}{
+		"/users/update":            {"user_id": reviewer, "username": "TestRenamed"},
+		"/users/setIsActive":       {"user_id": reviewer, "is_active": true},
+		"/users/setMaxOpenReviews": {"user_id": reviewer, "max_open_reviews": 3},
+	}
+	for path, body := range updates {
+		respUpdate := helpers.PatchJSON(t, path, body, helpers.AdminToken)
+		_ = respUpdate.Body.Close()
+		helpers.RequireStatusCode(t, respUpdate, http.StatusNotFound)
+	}
+
+	respIdle := helpers.DeleteJSON(t, "/users/delete/test_dir_del_missing", helpers.AdminToken)
+	_ = respIdle.Body.Close()
+	helpers.RequireStatusCode(t, respIdle, http.StatusNotFound)
+}
+
+func TestUserDelete_KeepsMergedHistory(t *testing.T) {
+	helpers.AddTeam(t, "test_dir_hist", "test_dir_hist_a", "test_dir_hist_b", "test_dir_hist_c")
+
+	merged := helpers.CreatePullRequest(t, "test_dir_hist_merged", "test_dir_hist_a")
+	require.Len(t, merged.AssignedReviewers, 2)
+	reviewer := merged.AssignedReviewers[0]
+
+	respMerge := helpers.PatchJSON(t, "/pullRequest/merge", map[string]interface{}{"pull_request_id": "test_dir_hist_merged"}, helpers.AdminToken)
+	_ = respMerge.Body.Close()
+	helpers.RequireStatusCode(t, respMerge, http.StatusOK)
+
+	helpers.CreatePullRequest(t, "test_dir_hist_open", "test_dir_hist_a")
+
+	var before []domain.TeamStats
+	helpers.GetStats(t, "/stats/teams?team=test_dir_hist&status=MERGED", &before)
+	require.Len(t, before, 1)
+	require.Equal(t, 2, before[0].MergedReviewCount)
+
+	respAuthor := helpers.DeleteJSON(t, "/users/delete/test_dir_hist_a?force=true", helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respAuthor.Body)
+	helpers.RequireStatusCode(t, respAuthor, http.StatusOK)
+
+	var out struct {
+		Result domain.UserDeletion `json:"result"`
+	}
+	require.NoError(t, json.NewDecoder(respAuthor.Body).Decode(&out), "decode response")
+	assert.Equal(t, []string{"test_dir_hist_open"}, out.Result.ClosedPullRequests)
+
+	respReviewer := helpers.DeleteJSON(t, "/users/delete/"+reviewer+"?force=true", helpers.AdminToken)
+	_ = respReviewer.Body.Close()
+	helpers.RequireStatusCode(t, respReviewer, http.StatusOK)
+
+	respGet := helpers.GetJSON(t, "/pullRequest/get?pull_request_id=test_dir_hist_merged", nil, helpers.UserToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respGet.Body)
+	helpers.RequireStatusCode(t, respGet, http.StatusOK)
+
+	var got struct {
+		PR domain.PullRequest `json:"pr"`
+	}
+	require.NoError(t, json.NewDecoder(respGet.Body).Decode(&got), "decode response")
+	assert.Equal(t, domain.MERGED, got.PR.Status)
+	assert.Contains(t, got.PR.AssignedReviewers, reviewer)
+
+	var after []domain.TeamStats
+	helpers.GetStats(t, "/stats/teams?team=test_dir_hist&status=MERGED", &after)
+	require.Len(t, after, 1)
+	assert.Equal(t, 2, after[0].MergedReviewCount)
+}
@@ -82,3 +82,56 @@ func TestGetUserReview_NotEmptyList(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 1, len(out.PR))
 }
+
+func TestGetUserReview_FiltersDetailsAndPagination(t *testing.T) {
+	helpers.AddTeam(t, "test_review_pages", "test_review_pages_a", "test_review_pages_b", "test_review_pages_c")
+
+	for _, prID := range []string{"test_review_pages_pr1", "test_review_pages_pr2", "test_review_pages_pr3"} {
+		pr := helpers.CreatePullRequest(t, prID, "test_review_pages_a")
+		require.Contains(t, pr.AssignedReviewers, "test_review_pages_b")
+	}
+
+	respMerge := helpers.PatchJSON(t, "/pullRequest/merge", map[string]interface{}{"pull_request_id": "test_review_pages_pr1"}, helpers.AdminToken)
+	_ = respMerge.Body.Close()
+	helpers.RequireStatusCode(t, respMerge, http.StatusOK)
+
+	type page struct {
+		PullRequests []domain.PullRequest `json:"pull_requests"`
+		NextCursor   string               `json:"next_cursor"`
+	}
+	get := func(path string) page {
+		resp := helpers.GetJSON(t, path, nil, helpers.UserToken)
+		defer func(Body io.ReadCloser) {
+			_ = Body.Close()
+		}(resp.Body)
+		helpers.RequireStatusCode(t, resp, http.StatusOK)
+
+		var out page
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out), "decode response")
+		return out
+	}
+
+	open := get("/users/getReview/test_review_pages_b")
+	require.Len(t, open.PullRequests, 2)
+	assert.Equal(t, "test_review_pages_pr2", open.PullRequests[0].PullRequestID)
+	assert.Empty(t, open.PullRequests[0].AssignedReviewers, "short form has no reviewers")
+
+	all := get("/users/getReview/test_review_pages_b?status=all")
+	assert.Len(t, all.PullRequests, 3)
+
+	first := get("/users/getReview/test_review_pages_b?status=OPEN,MERGED&order=desc&limit=2&details=true")
+	require.Len(t, first.PullRequests, 2)
+	assert.Equal(t, "test_review_pages_pr3", first.PullRequests[0].PullRequestID)
+	assert.ElementsMatch(t, []string{"test_review_pages_b", "test_review_pages_c"}, first.PullRequests[0].AssignedReviewers)
+	assert.False(t, first.PullRequests[0].CreatedAt.IsZero())
+	require.NotEmpty(t, first.NextCursor)
+
+	second := get("/users/getReview/test_review_pages_b?status=OPEN,MERGED&order=desc&limit=2&cursor=" + first.NextCursor)
+	require.Len(t, second.PullRequests, 1)
+	assert.Equal(t, "test_review_pages_pr1", second.PullRequests[0].PullRequestID)
+	assert.Empty(t, second.NextCursor)
+
+	respBad := helpers.GetJSON(t, "/users/getReview/test_review_pages_b?status=UNKNOWN", nil, helpers.UserToken)
+	_ = respBad.Body.Close()
+	helpers.RequireStatusCode(t, respBad, http.StatusBadRequest)
+}
@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/leoscrowi/pr-assignment-service/domain"
+	"github.com/leoscrowi/pr-assignment-service/tests/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type moveTeamResponse struct {
+	User          domain.User                 `json:"user"`
+	Move          *domain.TeamMoveRecord      `json:"move"`
+	Reassignments []domain.ReassignmentResult `json:"reassignments"`
+}
+
+func moveTeam(t *testing.T, path, userID, teamName string) moveTeamResponse {
+	t.Helper()
+
+	resp := helpers.PatchJSON(t, path, map[string]interface{}{"user_id": userID, "team_name": teamName}, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+	helpers.RequireStatusCode(t, resp, http.StatusOK)
+
+	var out moveTeamResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out), "decode response")
+	return out
+}
+
+func TestUserMoveTeam_ReassignsReviews(t *testing.T) {
+	helpers.AddTeam(t, "test_move_from", "test_move_a1", "test_move_a2", "test_move_a3", "test_move_a4")
+	helpers.AddTeam(t, "test_move_to", "test_move_b1")
+
+	pr := helpers.CreatePullRequest(t, "test_move_pr", "test_move_a1")
+	require.Len(t, pr.AssignedReviewers, 2)
+	moved := pr.AssignedReviewers[0]
+
+	out := moveTeam(t, "/users/moveTeam?reassign=true", moved, "test_move_to")
+	assert.Equal(t, "test_move_to", out.User.TeamName)
+	require.NotNil(t, out.Move)
+	assert.Equal(t, "test_move_from", out.Move.FromTeam)
+	assert.Equal(t, "test_move_to", out.Move.ToTeam)
+
+	require.Len(t, out.Reassignments, 1)
+	assert.Equal(t, "test_move_pr", out.Reassignments[0].PullRequestID)
+	assert.Equal(t, []string{moved}, out.Reassignments[0].RemovedReviewers)
+	assert.Len(t, out.Reassignments[0].AssignedReviewers, 2)
+	assert.NotContains(t, out.Reassignments[0].AssignedReviewers, moved)
+
+	same := moveTeam(t, "/users/moveTeam", moved, "test_move_to")
+	assert.Nil(t, same.Move)
+}
+
+func TestUserMoveTeam_KeepsReviews(t *testing.T) {
+	helpers.AddTeam(t, "test_move_keep_from", "test_move_keep_a1", "test_move_keep_a2")
+	helpers.AddTeam(t, "test_move_keep_to", "test_move_keep_b1")
+
+	helpers.CreatePullRequest(t, "test_move_keep_pr", "test_move_keep_a1")
+
+	out := moveTeam(t, "/users/moveTeam", "test_move_keep_a2", "test_move_keep_to")
+	assert.Empty(t, out.Reassignments)
+
+	respGet := helpers.GetJSON(t, "/pullRequest/get?pull_request_id=test_move_keep_pr", nil, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(respGet.Body)
+	helpers.RequireStatusCode(t, respGet, http.StatusOK)
+
+	var got struct {
+		PR domain.PullRequest `json:"pr"`
+	}
+	require.NoError(t, json.NewDecoder(respGet.Body).Decode(&got), "decode response")
+	assert.Equal(t, []string{"test_move_keep_a2"}, got.PR.AssignedReviewers)
+	assert.Empty(t, got.PR.FallbackReviewers, "the review stays with the team it was assigned for")
+
+	respMissing := helpers.PatchJSON(t, "/users/moveTeam", map[string]interface{}{"user_id": "test_move_keep_a2", "team_name": "test_move_missing"}, helpers.AdminToken)
+	_ = respMissing.Body.Close()
+	helpers.RequireStatusCode(t, respMissing, http.StatusNotFound)
+}
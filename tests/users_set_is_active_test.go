@@ -127,3 +127,46 @@ func TestSetUserIsActive_Unauthorized(t *testing.T) {
 	assert.Contains(t, errorResp, "error")
 	assert.NotEmpty(t, errorResp["error"])
 }
+
+func TestSetUserIsActive_ReassignsOpenReviews(t *testing.T) {
+	team := map[string]interface{}{
+		"team_name": "test_auto_reassign_team",
+		"members": []map[string]interface{}{
+			{"user_id": "test_ar_u1", "username": "TestAlice", "is_active": true},
+			{"user_id": "test_ar_u2", "username": "TestBob", "is_active": true},
+			{"user_id": "test_ar_u3", "username": "TestCharlie", "is_active": true},
+			{"user_id": "test_ar_u4", "username": "TestSarah", "is_active": true},
+		},
+	}
+
+	respAdd := helpers.PostJSON(t, "/team/add", team, helpers.AdminToken)
+	_ = respAdd.Body.Close()
+	helpers.RequireStatusCode(t, respAdd, http.StatusCreated)
+
+	pr := helpers.CreatePullRequest(t, "test_ar_pr", "test_ar_u1")
+	require.Len(t, pr.AssignedReviewers, 2)
+	leaving := pr.AssignedReviewers[0]
+
+	resp := helpers.PatchJSON(t, "/users/setIsActive?reassign=true", map[string]interface{}{
+		"user_id":   leaving,
+		"is_active": false,
+	}, helpers.AdminToken)
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+	helpers.RequireStatusCode(t, resp, http.StatusOK)
+
+	var out struct {
+		User          domain.User                 `json:"user"`
+		Reassignments []domain.ReassignmentResult `json:"reassignments"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out), "decode response")
+
+	assert.False(t, out.User.IsActive)
+	require.Len(t, out.Reassignments, 1)
+	assert.Equal(t, "test_ar_pr", out.Reassignments[0].PullRequestID)
+	assert.Equal(t, []string{leaving}, out.Reassignments[0].RemovedReviewers)
+	require.Len(t, out.Reassignments[0].AddedReviewers, 1)
+	assert.NotContains(t, out.Reassignments[0].AssignedReviewers, leaving)
+	assert.Len(t, out.Reassignments[0].AssignedReviewers, 2)
+}